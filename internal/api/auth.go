@@ -0,0 +1,73 @@
+package api
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/auth"
+)
+
+const sessionKey = "session"
+
+// Authenticate verifies the bearer session token and makes sure it was
+// issued for the game addressed by the :code route param.
+func Authenticate(tokens *auth.TokenManager) gin.HandlerFunc {
+	return authenticate(tokens, false)
+}
+
+// AuthenticateSocket is Authenticate for the WebSocket route: the token may
+// also come from the ?token= query, since browser WebSockets cannot set
+// headers. REST routes do not accept it, so tokens stay out of their URLs.
+func AuthenticateSocket(tokens *auth.TokenManager) gin.HandlerFunc {
+	return authenticate(tokens, true)
+}
+
+func authenticate(tokens *auth.TokenManager, allowQuery bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok && allowQuery {
+			token = c.Query("token")
+		}
+		if token == "" {
+			Unauthorized(c, "missing session token")
+			c.Abort()
+			return
+		}
+
+		claims, err := tokens.Verify(token)
+		if err != nil {
+			Unauthorized(c, err.Error())
+			c.Abort()
+			return
+		}
+
+		if claims.GameCode != c.Param("code") {
+			Forbidden(c, "session does not belong to this game")
+			c.Abort()
+			return
+		}
+
+		c.Set(sessionKey, claims)
+		c.Next()
+	}
+}
+
+// SessionFrom returns the claims stored by Authenticate.
+func SessionFrom(c *gin.Context) auth.Claims {
+	return c.MustGet(sessionKey).(auth.Claims)
+}
+
+// RequireAdmin guards the admin API with a static bearer token. An empty
+// token disables the admin API entirely.
+func RequireAdmin(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			Unauthorized(c, "invalid admin token")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
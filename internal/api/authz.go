@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+type Role int
+
+const (
+	// RoleMember is any player still seated in the game.
+	RoleMember Role = iota
+	// RoleHost is the player flagged with players.is_host.
+	RoleHost
+	// RoleDrawer is the current player of the target round, or of the
+	// latest round when there is no target round.
+	RoleDrawer
+	// RoleSelf is the target player.
+	RoleSelf
+)
+
+const playerKey = "player"
+
+// Target is what a request acts on. Zero fields are unset.
+type Target struct {
+	RoundID  int64
+	PlayerID int64
+}
+
+type Authorizer struct {
+	logger *slog.Logger
+	store  database.Store
+}
+
+func NewAuthorizer(store database.Store, logger *slog.Logger) *Authorizer {
+	return &Authorizer{
+		logger: logger,
+		store:  store,
+	}
+}
+
+// Require resolves the calling player from the session and lets the request
+// through when they hold at least one of the given roles. The target comes
+// from the :id and :player_id route params. It must run after Authenticate.
+func (a *Authorizer) Require(roles ...Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := SessionFrom(c)
+
+		var target Target
+		if id := c.Param("id"); id != "" {
+			roundID, err := utils.ParseID(id)
+			if err != nil {
+				BadRequest(c, "invalid round id")
+				c.Abort()
+				return
+			}
+			target.RoundID = roundID
+		}
+		if id := c.Param("player_id"); id != "" {
+			playerID, err := utils.ParseID(id)
+			if err != nil {
+				BadRequest(c, "invalid player ID")
+				c.Abort()
+				return
+			}
+			target.PlayerID = playerID
+		}
+
+		player, err := a.Check(c.Request.Context(), session.GameID, session.PlayerID, target, roles...)
+		if err != nil {
+			respondError(c, a.logger, err, "db error")
+			c.Abort()
+			return
+		}
+
+		c.Set(playerKey, player)
+		c.Next()
+	}
+}
+
+// Check loads the player and returns errForbidden unless they hold at least
+// one of roles for target. It backs both the REST middleware and socket
+// commands.
+func (a *Authorizer) Check(ctx context.Context, gameID, playerID int64, target Target, roles ...Role) (database.Player, error) {
+	player, err := a.store.GetPlayerInGame(ctx, database.GetPlayerInGameParams{
+		ID:     playerID,
+		GameID: gameID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return player, errNotAMember
+		}
+		return player, err
+	}
+	if player.LeftAt.Valid {
+		return player, errNotAMember
+	}
+
+	for _, role := range roles {
+		ok, err := a.hasRole(ctx, player, role, target)
+		if err != nil {
+			return player, err
+		}
+		if ok {
+			return player, nil
+		}
+	}
+	return player, errForbidden
+}
+
+func (a *Authorizer) hasRole(ctx context.Context, player database.Player, role Role, target Target) (bool, error) {
+	switch role {
+	case RoleMember:
+		return true, nil
+	case RoleHost:
+		return player.IsHost.Bool, nil
+	case RoleSelf:
+		return target.PlayerID == player.ID, nil
+	case RoleDrawer:
+		return a.isDrawer(ctx, player, target.RoundID)
+	}
+	return false, nil
+}
+
+func (a *Authorizer) isDrawer(ctx context.Context, player database.Player, roundID int64) (bool, error) {
+	var (
+		round database.Round
+		err   error
+	)
+	if roundID == 0 {
+		round, err = a.store.GetLatestRoundInGame(ctx, player.GameID)
+	} else {
+		round, err = a.store.GetRoundByID(ctx, roundID)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, errRoundNotFound
+		}
+		return false, err
+	}
+
+	if round.GameID != player.GameID {
+		return false, errRoundNotFound
+	}
+	return round.CurrentPlayerID == player.ID, nil
+}
+
+// playerFrom returns the calling player resolved by Authorizer.Require.
+func playerFrom(c *gin.Context) database.Player {
+	return c.MustGet(playerKey).(database.Player)
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// CommandHandler runs socket commands through the same authorization and
+// round logic as the REST routes.
+type CommandHandler struct {
+	logger *slog.Logger
+	authz  *Authorizer
+	rounds *RoundsHandler
+}
+
+func NewCommandHandler(rounds *RoundsHandler, authz *Authorizer, logger *slog.Logger) *CommandHandler {
+	return &CommandHandler{
+		logger: logger,
+		authz:  authz,
+		rounds: rounds,
+	}
+}
+
+type DrawCardCommand struct {
+	RoundID int64 `json:"roundId"`
+}
+
+type AnswerCommand struct {
+	RoundID int64 `json:"roundId"`
+}
+
+type VoteCommand struct {
+	RoundID int64 `json:"roundId"`
+	Accept  *bool `json:"accept"`
+}
+
+// NextRoundCommand may carry the picked player; see NextRoundRequest.
+type NextRoundCommand struct {
+	PlayerID int64 `json:"playerId"`
+}
+
+type KickPlayerCommand struct {
+	PlayerID int64 `json:"playerId"`
+}
+
+type TransferHostCommand struct {
+	PlayerID int64 `json:"playerId"`
+}
+
+// Handle is a ws.CommandFunc.
+func (h *CommandHandler) Handle(ctx context.Context, caller ws.Caller, cmd ws.Command) (any, error) {
+	result, err := h.dispatch(ctx, caller, cmd)
+	if err != nil {
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			h.logger.Error("socket command failed", "type", cmd.Type, "error", err)
+			return nil, &ws.CommandError{Status: status, Message: "something went wrong"}
+		}
+		return nil, &ws.CommandError{Status: status, Message: err.Error()}
+	}
+	return result, nil
+}
+
+func (h *CommandHandler) dispatch(ctx context.Context, caller ws.Caller, cmd ws.Command) (any, error) {
+	switch cmd.Type {
+	case "draw_card":
+		var data DrawCardCommand
+		if err := decodeCommand(cmd, &data); err != nil || data.RoundID == 0 {
+			return nil, fmt.Errorf("%w: roundId is required", errBadCommand)
+		}
+		if err := h.authorize(ctx, caller, Target{RoundID: data.RoundID}, RoleDrawer); err != nil {
+			return nil, err
+		}
+		return h.rounds.drawCard(ctx, caller.GameCode, data.RoundID)
+
+	case "answer":
+		var data AnswerCommand
+		if err := decodeCommand(cmd, &data); err != nil || data.RoundID == 0 {
+			return nil, fmt.Errorf("%w: roundId is required", errBadCommand)
+		}
+		if err := h.authorize(ctx, caller, Target{RoundID: data.RoundID}, RoleDrawer); err != nil {
+			return nil, err
+		}
+		return h.rounds.answerRound(ctx, caller.GameCode, data.RoundID)
+
+	case "vote":
+		var data VoteCommand
+		if err := decodeCommand(cmd, &data); err != nil || data.RoundID == 0 || data.Accept == nil {
+			return nil, fmt.Errorf("%w: roundId and accept are required", errBadCommand)
+		}
+		if err := h.authorize(ctx, caller, Target{}, RoleMember); err != nil {
+			return nil, err
+		}
+		return h.rounds.vote(ctx, caller.GameCode, data.RoundID, caller.PlayerID, *data.Accept)
+
+	case "next_round":
+		var data NextRoundCommand
+		if len(cmd.Data) > 0 {
+			if err := decodeCommand(cmd, &data); err != nil {
+				return nil, fmt.Errorf("%w: invalid playerId", errBadCommand)
+			}
+		}
+		// 主持人以外，輪到挑人的玩家也可以開下一回合，由 nextRound 檢查
+		if err := h.authorize(ctx, caller, Target{}, RoleMember); err != nil {
+			return nil, err
+		}
+		round, err := h.rounds.nextRound(ctx, caller.GameCode, caller.PlayerID, data.PlayerID)
+		if err != nil {
+			return nil, err
+		}
+		return gin.H{
+			"roundId":  round.ID,
+			"playerId": round.CurrentPlayerID,
+		}, nil
+
+	case "kick_player":
+		var data KickPlayerCommand
+		if err := decodeCommand(cmd, &data); err != nil || data.PlayerID == 0 {
+			return nil, fmt.Errorf("%w: playerId is required", errBadCommand)
+		}
+		if err := h.authorize(ctx, caller, Target{PlayerID: data.PlayerID}, RoleHost, RoleSelf); err != nil {
+			return nil, err
+		}
+		if err := h.rounds.removePlayer(ctx, caller.GameCode, data.PlayerID); err != nil {
+			return nil, err
+		}
+		return gin.H{"playerId": data.PlayerID}, nil
+
+	case "transfer_host":
+		var data TransferHostCommand
+		if err := decodeCommand(cmd, &data); err != nil || data.PlayerID == 0 {
+			return nil, fmt.Errorf("%w: playerId is required", errBadCommand)
+		}
+		if err := h.authorize(ctx, caller, Target{}, RoleHost); err != nil {
+			return nil, err
+		}
+		return h.rounds.transferHost(ctx, caller.GameCode, caller.PlayerID, data.PlayerID)
+
+	case "end_game":
+		if err := h.authorize(ctx, caller, Target{}, RoleHost); err != nil {
+			return nil, err
+		}
+		return h.rounds.endGame(ctx, caller.GameCode)
+	}
+
+	return nil, fmt.Errorf("%w: unknown type %q", errBadCommand, cmd.Type)
+}
+
+func (h *CommandHandler) authorize(ctx context.Context, caller ws.Caller, target Target, roles ...Role) error {
+	_, err := h.authz.Check(ctx, caller.GameID, caller.PlayerID, target, roles...)
+	return err
+}
+
+func decodeCommand(cmd ws.Command, v any) error {
+	if len(cmd.Data) == 0 {
+		return errBadCommand
+	}
+	return json.Unmarshal(cmd.Data, v)
+}
@@ -14,6 +14,10 @@ func InternalServerError(c *gin.Context, msg string) {
 	c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
 }
 
+func Unauthorized(c *gin.Context, msg string) {
+	c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
+}
+
 func NotFound(c *gin.Context, msg string) {
 	c.JSON(http.StatusNotFound, gin.H{"error": msg})
 
@@ -28,6 +32,10 @@ func Forbidden(c *gin.Context, msg string) {
 
 }
 
+func Conflict(c *gin.Context, msg string) {
+	c.JSON(http.StatusConflict, gin.H{"error": msg})
+}
+
 func Success(c *gin.Context, data any) {
 	c.JSON(http.StatusOK, gin.H{
 		"message": "success",
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+const maxDeckNameLength = 100 // decks.name 是 VARCHAR(100)
+
+// errInvalidDeckQuestions aborts the transaction when some question IDs
+// cannot be added; the details are reported through FailedValidation.
+var errInvalidDeckQuestions = errors.New("invalid deck questions")
+
+type DecksHandler struct {
+	logger *slog.Logger
+	store  database.Store
+}
+
+func NewDecksHandler(store database.Store, logger *slog.Logger) *DecksHandler {
+	return &DecksHandler{
+		logger: logger,
+		store:  store,
+	}
+}
+
+type CreateDeckRequest struct {
+	Name        string  `json:"name"`
+	QuestionIDs []int64 `json:"questionIds"`
+}
+
+type DeckQuestionsRequest struct {
+	QuestionIDs []int64 `json:"questionIds" binding:"required,min=1"`
+}
+
+type DeckResponse struct {
+	ID        int64              `json:"id"`
+	Name      string             `json:"name"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Questions []QuestionResponse `json:"questions,omitempty"`
+}
+
+func (h *DecksHandler) ListDecks(c *gin.Context) {
+	decks, err := h.store.ListPublicDecks(c.Request.Context())
+	if err != nil {
+		h.logger.Error("list decks failed", "error", err)
+		InternalServerError(c, "failed to list decks")
+		return
+	}
+
+	resp := make([]DeckResponse, 0, len(decks))
+	for _, d := range decks {
+		resp = append(resp, toDeckResponse(d, nil))
+	}
+	Success(c, resp)
+}
+
+func (h *DecksHandler) CreateDeck(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req CreateDeckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request format")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	switch {
+	case req.Name == "":
+		FailedValidation(c, map[string]string{"name": "is required"})
+		return
+	case utf8.RuneCountInString(req.Name) > maxDeckNameLength:
+		FailedValidation(c, map[string]string{"name": "must be at most 100 characters"})
+		return
+	}
+
+	var (
+		deck      database.Deck
+		questions []database.Question
+		errs      map[string]string
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		deck, err = q.CreateDeck(ctx, database.CreateDeckParams{Name: req.Name})
+		if err != nil {
+			return err
+		}
+
+		errs, err = addDeckQuestions(ctx, q, deck.ID, req.QuestionIDs)
+		if err != nil {
+			return err
+		}
+
+		questions, err = q.ListDeckQuestions(ctx, deck.ID)
+		return err
+	})
+	if err != nil {
+		h.handleDeckError(c, err, errs, "failed to create deck")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"data":    toDeckResponse(deck, questions),
+	})
+}
+
+func (h *DecksHandler) GetDeck(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid deck id")
+		return
+	}
+
+	deck, err := h.store.GetPublicDeck(ctx, id)
+	if err != nil {
+		h.handleDeckError(c, err, nil, "failed to get deck")
+		return
+	}
+
+	questions, err := h.store.ListDeckQuestions(ctx, deck.ID)
+	if err != nil {
+		h.handleDeckError(c, err, nil, "failed to get deck")
+		return
+	}
+
+	Success(c, toDeckResponse(deck, questions))
+}
+
+func (h *DecksHandler) AddQuestions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid deck id")
+		return
+	}
+
+	var req DeckQuestionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "questionIds is required")
+		return
+	}
+
+	var (
+		deck      database.Deck
+		questions []database.Question
+		errs      map[string]string
+	)
+	err = h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		deck, err = q.GetPublicDeck(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		errs, err = addDeckQuestions(ctx, q, deck.ID, req.QuestionIDs)
+		if err != nil {
+			return err
+		}
+
+		questions, err = q.ListDeckQuestions(ctx, deck.ID)
+		return err
+	})
+	if err != nil {
+		h.handleDeckError(c, err, errs, "failed to add questions")
+		return
+	}
+
+	Success(c, toDeckResponse(deck, questions))
+}
+
+func (h *DecksHandler) RemoveQuestion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	deckID, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid deck id")
+		return
+	}
+	questionID, err := utils.ParseID(c.Param("question_id"))
+	if err != nil {
+		BadRequest(c, "invalid question id")
+		return
+	}
+
+	if _, err := h.store.GetPublicDeck(ctx, deckID); err != nil {
+		h.handleDeckError(c, err, nil, "failed to remove question")
+		return
+	}
+
+	removed, err := h.store.RemoveQuestionFromDeck(ctx, database.RemoveQuestionFromDeckParams{
+		DeckID:     deckID,
+		QuestionID: questionID,
+	})
+	if err != nil {
+		h.handleDeckError(c, err, nil, "failed to remove question")
+		return
+	}
+	if removed == 0 {
+		NotFound(c, "question is not in this deck")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *DecksHandler) handleDeckError(c *gin.Context, err error, errs map[string]string, msg string) {
+	switch {
+	case errors.Is(err, errInvalidDeckQuestions):
+		FailedValidation(c, errs)
+	case errors.Is(err, sql.ErrNoRows):
+		NotFound(c, "deck not found")
+	default:
+		h.logger.Error(msg, "error", err)
+		InternalServerError(c, msg)
+	}
+}
+
+// addDeckQuestions adds global questions to a deck. Unknown, deleted or
+// game-private questions are reported per index and abort the transaction.
+func addDeckQuestions(ctx context.Context, q database.Store, deckID int64, ids []int64) (map[string]string, error) {
+	errs := map[string]string{}
+	for i, id := range ids {
+		if _, err := q.GetActiveQuestion(ctx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				errs[fmt.Sprintf("questionIds[%d]", i)] = "question not found"
+				continue
+			}
+			return nil, err
+		}
+
+		err := q.AddQuestionToDeck(ctx, database.AddQuestionToDeckParams{
+			DeckID:     deckID,
+			QuestionID: id,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs, errInvalidDeckQuestions
+	}
+	return nil, nil
+}
+
+func toDeckResponse(d database.Deck, questions []database.Question) DeckResponse {
+	resp := DeckResponse{
+		ID:        d.ID,
+		Name:      d.Name,
+		CreatedAt: d.CreatedAt.Time,
+	}
+	for _, q := range questions {
+		resp.Questions = append(resp.Questions, toQuestionResponse(q))
+	}
+	return resp
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+// drawnContents draws n questions for the game the way new rounds do and
+// returns the set of their contents.
+func drawnContents(t *testing.T, h *RoundsHandler, store database.Store, code string, n int) map[string]bool {
+	t.Helper()
+	ctx := context.Background()
+	drawn := map[string]bool{}
+	for range n {
+		game, err := store.GetGameByCode(ctx, code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		question, err := h.drawQuestion(ctx, store, game)
+		if err != nil {
+			t.Fatal(err)
+		}
+		drawn[question.Content] = true
+	}
+	return drawn
+}
+
+func TestGameDecks(t *testing.T) {
+	ctx := context.Background()
+	clock := utils.NewFakeClock(time.Unix(1_700_000_000, 0))
+	store := memory.New(clock)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	games := NewGamesHandler(store, logger, utils.NewSeededRandom(2), clock)
+	rounds := newTestRoundsHandler(t, store)
+
+	var global []int64
+	for _, content := range []string{"global a", "global b", "global c", "global d"} {
+		q, err := store.CreateQuestion(ctx, database.CreateQuestionParams{Level: "easy", Content: content})
+		if err != nil {
+			t.Fatal(err)
+		}
+		global = append(global, q.ID)
+	}
+	newDeck := func(name string, ids ...int64) database.Deck {
+		deck, err := store.CreateDeck(ctx, database.CreateDeckParams{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := addDeckQuestions(ctx, store, deck.ID, ids); err != nil {
+			t.Fatal(err)
+		}
+		return deck
+	}
+	deck := newDeck("party", global[0], global[2])
+	empty := newDeck("empty")
+
+	createGame := func(body gin.H, want int) CreateGameResponse {
+		t.Helper()
+		var resp CreateGameResponse
+		callJSON(t, games.CreateGame, http.MethodPost, "/", "", body, want, &resp)
+		return resp
+	}
+	expect := func(name string, got map[string]bool, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s drew %v, want %v", name, got, want)
+		}
+		for _, content := range want {
+			if !got[content] {
+				t.Fatalf("%s drew %v, want %v", name, got, want)
+			}
+		}
+	}
+
+	// 選了牌組就只抽牌組裡的題目
+	withDeck := createGame(gin.H{"level": "easy", "deckId": deck.ID}, http.StatusOK)
+	if withDeck.DeckID == nil || *withDeck.DeckID != deck.ID {
+		t.Fatalf("game deck %v, want %d", withDeck.DeckID, deck.ID)
+	}
+	expect("deck game", drawnContents(t, rounds, store, withDeck.Code, 6), "global a", "global c")
+
+	// 自訂題目只屬於這一局
+	private := createGame(gin.H{"level": "easy", "questions": []string{"secret a", "secret b"}}, http.StatusOK)
+	if private.DeckID == nil {
+		t.Fatal("inline questions did not create a deck")
+	}
+	expect("private game", drawnContents(t, rounds, store, private.Code, 6), "secret a", "secret b")
+
+	plain := createGame(gin.H{"level": "easy"}, http.StatusOK)
+	expect("plain game", drawnContents(t, rounds, store, plain.Code, 8), "global a", "global b", "global c", "global d")
+
+	createGame(gin.H{"level": "easy", "deckId": *private.DeckID}, http.StatusNotFound)
+	list, err := store.ListQuestions(ctx, database.ListQuestionsParams{Limit: maxPageSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != len(global) {
+		t.Fatalf("admin list has %d questions, want only the %d global ones", len(list), len(global))
+	}
+	secrets, err := store.ListDeckQuestionIDs(ctx, *private.DeckID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addDeckQuestions(ctx, store, deck.ID, secrets); !errors.Is(err, errInvalidDeckQuestions) {
+		t.Fatalf("adding private questions to a public deck: got %v", err)
+	}
+
+	// 空的牌組退回同等級的題庫
+	fallback := createGame(gin.H{"level": "easy", "deckId": empty.ID}, http.StatusOK)
+	expect("empty deck game", drawnContents(t, rounds, store, fallback.Code, 8), "global a", "global b", "global c", "global d")
+}
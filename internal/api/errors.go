@@ -0,0 +1,139 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+)
+
+var (
+	errGameNotFound    = errors.New("game not found")
+	errRoundNotFound   = errors.New("round not found")
+	errPlayerNotInGame = errors.New("player is not in this game")
+	errNotAMember      = errors.New("player is no longer in this game")
+	errForbidden       = errors.New("you are not allowed to do this")
+	errBadCommand      = errors.New("invalid command")
+	errDeckNotFound    = errors.New("deck not found")
+	errNoQuestions     = errors.New("no questions available for this game")
+	errNotVoting       = errors.New("round is not being voted on")
+	errInvalidSeats    = errors.New("playerIds must list every player in the game exactly once")
+	errSeatsLocked     = errors.New("seats can only be changed before the game starts")
+	errNotVerifiable   = errors.New("round was drawn before draws were committed and is not verifiable")
+)
+
+// errorStatus maps a known error to its HTTP status. Unknown errors report
+// http.StatusInternalServerError.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, errGameNotFound),
+		errors.Is(err, errRoundNotFound),
+		errors.Is(err, errDeckNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errPlayerNotInGame),
+		errors.Is(err, errBadCommand),
+		errors.Is(err, errInvalidSeats):
+		return http.StatusBadRequest
+	case errors.Is(err, errNotAMember), errors.Is(err, errForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrIllegalTransition),
+		errors.Is(err, domain.ErrGameNotStarted),
+		errors.Is(err, domain.ErrGameEnded),
+		errors.Is(err, domain.ErrRoundInProgress),
+		errors.Is(err, domain.ErrVotingOff),
+		errors.Is(err, domain.ErrAnswerNotAccepted),
+		errors.Is(err, domain.ErrPickRequired),
+		errors.Is(err, domain.ErrPickNotAllowed),
+		errors.Is(err, errNoQuestions),
+		errors.Is(err, errNotVoting),
+		errors.Is(err, errSeatsLocked),
+		errors.Is(err, errNotVerifiable):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// isUniqueViolation reports whether Postgres rejected a row because it
+// breaks a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// respondError writes the response for an error coming out of a
+// transactional flow through the helper for its status. Unknown errors are
+// logged and reported as msg.
+func respondError(c *gin.Context, logger *slog.Logger, err error, msg string) {
+	switch errorStatus(err) {
+	case http.StatusNotFound:
+		NotFound(c, err.Error())
+	case http.StatusBadRequest:
+		BadRequest(c, err.Error())
+	case http.StatusForbidden:
+		Forbidden(c, err.Error())
+	case http.StatusConflict:
+		Conflict(c, err.Error())
+	default:
+		logger.Error(msg, "error", err)
+		InternalServerError(c, msg)
+	}
+}
+
+// lockGame loads the game by code and holds its row lock until the
+// surrounding transaction ends, serialising state changes per game.
+func lockGame(ctx context.Context, q database.Store, code string) (database.Game, error) {
+	game, err := q.LockGameByCode(ctx, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return game, errGameNotFound
+	}
+	return game, err
+}
+
+// activePlayer loads a player who is still in the game.
+func activePlayer(ctx context.Context, q database.Store, gameID, playerID int64) (database.Player, error) {
+	player, err := q.GetPlayerInGame(ctx, database.GetPlayerInGameParams{
+		ID:     playerID,
+		GameID: gameID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return player, errPlayerNotInGame
+		}
+		return player, err
+	}
+	if player.LeftAt.Valid {
+		return player, errPlayerNotInGame
+	}
+	return player, nil
+}
+
+// lockRound locks the game and loads one of its rounds, checking the game is
+// still being played.
+func lockRound(ctx context.Context, q database.Store, gameCode string, roundID int64) (database.Game, database.Round, error) {
+	game, err := lockGame(ctx, q, gameCode)
+	if err != nil {
+		return game, database.Round{}, err
+	}
+
+	if err := domain.CanPlay(domain.GameStatus(game.Status)); err != nil {
+		return game, database.Round{}, err
+	}
+
+	round, err := q.GetRoundByID(ctx, roundID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return game, round, errRoundNotFound
+		}
+		return game, round, err
+	}
+	if round.GameID != game.ID {
+		return game, round, errRoundNotFound
+	}
+	return game, round, nil
+}
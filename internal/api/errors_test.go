@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/domain"
+)
+
+func TestRespondError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		err    error
+		status int
+		body   string
+	}{
+		{errGameNotFound, http.StatusNotFound, errGameNotFound.Error()},
+		{fmt.Errorf("load: %w", errRoundNotFound), http.StatusNotFound, "load: round not found"},
+		{errInvalidSeats, http.StatusBadRequest, errInvalidSeats.Error()},
+		{errNotAMember, http.StatusForbidden, errNotAMember.Error()},
+		{errForbidden, http.StatusForbidden, errForbidden.Error()},
+		{domain.ErrGameEnded, http.StatusConflict, domain.ErrGameEnded.Error()},
+		{errNotVerifiable, http.StatusConflict, errNotVerifiable.Error()},
+		{errors.New("connection reset"), http.StatusInternalServerError, "failed to do it"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		respondError(c, logger, tt.err, "failed to do it")
+
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != tt.status || body.Error != tt.body {
+			t.Errorf("respondError(%v) = %d %q, want %d %q", tt.err, w.Code, body.Error, tt.status, tt.body)
+		}
+	}
+}
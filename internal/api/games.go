@@ -2,37 +2,112 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/questions"
 	"github.com/y3933y3933/joker/internal/utils"
 )
 
 type GamesHandler struct {
-	logger  *slog.Logger
-	queries *database.Queries
+	logger *slog.Logger
+	store  database.Store
+	rng    utils.Random
+	clock  utils.Clock
 }
 
-func NewGamesHandler(queries *database.Queries, logger *slog.Logger) *GamesHandler {
+func NewGamesHandler(store database.Store, logger *slog.Logger, rng utils.Random, clock utils.Clock) *GamesHandler {
 	return &GamesHandler{
-		logger:  logger,
-		queries: queries,
+		logger: logger,
+		store:  store,
+		rng:    rng,
+		clock:  clock,
 	}
 }
 
+// maxInlineQuestions caps the custom questions sent with CreateGame.
+const maxInlineQuestions = 200
+
+// CreateGameRequest takes either a deckId or an inline list of questions.
+// Inline questions become a deck private to the new game.
 type CreateGameRequest struct {
-	Level string `json:"level" binding:"required,oneof=easy normal spicy"`
+	Level     string                `json:"level" binding:"required,oneof=easy normal spicy"`
+	DeckID    *int64                `json:"deckId"`
+	Questions []string              `json:"questions"`
+	Rules     *CreateRulesRequest   `json:"rules"`
+	Timer     *CreateTimerRequest   `json:"timer"`
+	Scoring   *CreateScoringRequest `json:"scoring"`
+	// VoteRule is off, penalty or reanswer; it defaults to off.
+	VoteRule *string `json:"voteRule"`
+	// TurnOrder is sequential, random, loser_picks or host_assigned; it
+	// defaults to sequential.
+	TurnOrder *string `json:"turnOrder"`
+}
+
+// CreateRulesRequest overrides parts of domain.DefaultRules; omitted fields
+// keep their default.
+type CreateRulesRequest struct {
+	DrawMode         *string  `json:"drawMode"`
+	JokerProbability *float64 `json:"jokerProbability"`
+	PityDraws        *int     `json:"pityDraws"`
+	PileSafeCards    *int     `json:"pileSafeCards"`
+	PileJokers       *int     `json:"pileJokers"`
+}
+
+// CreateTimerRequest overrides parts of domain.DefaultTimer. A limit of
+// zero seconds leaves rounds untimed.
+type CreateTimerRequest struct {
+	AnswerTimeLimitSeconds *int    `json:"answerTimeLimitSeconds"`
+	TimeoutAction          *string `json:"timeoutAction"`
+}
+
+// CreateScoringRequest overrides the points of domain.DefaultScoring.
+type CreateScoringRequest struct {
+	Safe     *int `json:"safe"`
+	Joker    *int `json:"joker"`
+	Skipped  *int `json:"skipped"`
+	TimedOut *int `json:"timedOut"`
+}
+
+type GameTimer struct {
+	AnswerTimeLimitSeconds int    `json:"answerTimeLimitSeconds"`
+	TimeoutAction          string `json:"timeoutAction"`
+}
+
+type GameScoring struct {
+	Safe     int `json:"safe"`
+	Joker    int `json:"joker"`
+	Skipped  int `json:"skipped"`
+	TimedOut int `json:"timedOut"`
+}
+
+type GameRules struct {
+	DrawMode         string  `json:"drawMode"`
+	JokerProbability float64 `json:"jokerProbability"`
+	PityDraws        int     `json:"pityDraws"`
+	PileSafeCards    int     `json:"pileSafeCards"`
+	PileJokers       int     `json:"pileJokers"`
 }
 
 type CreateGameResponse struct {
-	ID        int64     `json:"id"`
-	Code      string    `json:"code"`
-	Level     string    `json:"level"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        int64       `json:"id"`
+	Code      string      `json:"code"`
+	Level     string      `json:"level"`
+	DeckID    *int64      `json:"deckId,omitempty"`
+	Rules     GameRules   `json:"rules"`
+	Timer     GameTimer   `json:"timer"`
+	Scoring   GameScoring `json:"scoring"`
+	VoteRule  string      `json:"voteRule"`
+	TurnOrder string      `json:"turnOrder"`
+	CreatedAt time.Time   `json:"createdAt"`
 }
 
 func (h *GamesHandler) CreateGame(c *gin.Context) {
@@ -49,28 +124,115 @@ func (h *GamesHandler) CreateGame(c *gin.Context) {
 		return
 	}
 
-	game, err := h.queries.CreateGame(ctx, database.CreateGameParams{
-		Code:   code,
-		Level:  req.Level,
-		Status: "waiting",
+	var game database.Game
+	err = h.store.ExecTx(ctx, func(q database.Store) error {
+		var deckID pgtype.Int8
+		if req.DeckID != nil {
+			deck, err := q.GetPublicDeck(ctx, *req.DeckID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return errDeckNotFound
+				}
+				return err
+			}
+			deckID = pgtype.Int8{Int64: deck.ID, Valid: true}
+		}
+
+		rules, timer, scoring := req.rules(), req.timer(), req.scoring()
+		game, err = q.CreateGame(ctx, database.CreateGameParams{
+			Code:                   code,
+			Level:                  req.Level,
+			Status:                 string(domain.GameWaiting),
+			DeckID:                 deckID,
+			DrawMode:               string(rules.Mode),
+			JokerProbability:       rules.JokerProbability,
+			PityDraws:              int32(rules.PityDraws),
+			PileSafeCards:          int32(rules.PileSafe),
+			PileJokers:             int32(rules.PileJokers),
+			AnswerTimeLimitSeconds: int32(timer.Limit / time.Second),
+			TimeoutAction:          string(timer.Action),
+			VoteRule:               string(req.voteRule()),
+			PointsSafe:             int32(scoring.Safe),
+			PointsJoker:            int32(scoring.Joker),
+			PointsSkipped:          int32(scoring.Skipped),
+			PointsTimedOut:         int32(scoring.TimedOut),
+			TurnOrder:              string(req.turnOrder()),
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(req.Questions) == 0 {
+			return nil
+		}
+		game.DeckID, err = h.createPrivateDeck(ctx, q, game, req.Questions)
+		return err
 	})
 	if err != nil {
-		h.logger.Error("create game fail: ", err)
-		InternalServerError(c, "failed to create game")
+		respondError(c, h.logger, err, "failed to create game")
 		return
 	}
 
-	Success(c, CreateGameResponse{
+	resp := CreateGameResponse{
 		ID:        game.ID,
 		Code:      game.Code,
 		Level:     game.Level,
+		Rules:     toGameRules(rulesFromGame(game)),
+		Timer:     toGameTimer(timerFromGame(game)),
+		Scoring:   toGameScoring(scoringFromGame(game)),
+		VoteRule:  game.VoteRule,
+		TurnOrder: game.TurnOrder,
 		CreatedAt: game.CreatedAt.Time,
+	}
+	if game.DeckID.Valid {
+		resp.DeckID = &game.DeckID.Int64
+	}
+	Success(c, resp)
+
+}
+
+// createPrivateDeck stores the inline questions as a deck that only this
+// game can draw from.
+func (h *GamesHandler) createPrivateDeck(ctx context.Context, q database.Store, game database.Game, contents []string) (pgtype.Int8, error) {
+	gameID := pgtype.Int8{Int64: game.ID, Valid: true}
+
+	deck, err := q.CreateDeck(ctx, database.CreateDeckParams{
+		Name:   "game " + game.Code,
+		GameID: gameID,
 	})
+	if err != nil {
+		return pgtype.Int8{}, err
+	}
+
+	for _, content := range contents {
+		question, err := q.CreatePrivateQuestion(ctx, database.CreatePrivateQuestionParams{
+			Level:   game.Level,
+			Content: content,
+			GameID:  gameID,
+		})
+		if err != nil {
+			return pgtype.Int8{}, err
+		}
+
+		err = q.AddQuestionToDeck(ctx, database.AddQuestionToDeckParams{
+			DeckID:     deck.ID,
+			QuestionID: question.ID,
+		})
+		if err != nil {
+			return pgtype.Int8{}, err
+		}
+	}
 
+	deckID := pgtype.Int8{Int64: deck.ID, Valid: true}
+	return deckID, q.UpdateGameDeck(ctx, database.UpdateGameDeckParams{
+		ID:        game.ID,
+		DeckID:    deckID,
+		UpdatedAt: pgtype.Timestamptz{Time: h.clock.Now(), Valid: true},
+	})
 }
 
 func generateGameCode(ctx context.Context, h *GamesHandler) (string, error) {
-	return utils.GenerateUniqueGameCode(ctx, h.queries, 6, 5)
+	return utils.GenerateUniqueGameCode(ctx, h.store, h.rng, 6, 5)
 }
 
 func bindCreateGameRequest(c *gin.Context, req *CreateGameRequest) error {
@@ -83,11 +245,189 @@ func bindCreateGameRequest(c *gin.Context, req *CreateGameRequest) error {
 		}
 		return err
 	}
+
+	errs := validateCustomQuestions(req)
+	for field, msg := range req.rules().Validate() {
+		errs["rules."+field] = msg
+	}
+	for field, msg := range req.timer().Validate() {
+		errs["timer."+field] = msg
+	}
+	for field, msg := range req.scoring().Validate() {
+		errs["scoring."+field] = msg
+	}
+	if !req.voteRule().Valid() {
+		errs["voteRule"] = "must be one of off, penalty, reanswer"
+	}
+	if !req.turnOrder().Valid() {
+		errs["turnOrder"] = "must be one of sequential, random, loser_picks, host_assigned"
+	}
+	if len(errs) > 0 {
+		FailedValidation(c, errs)
+		return errors.New("invalid create game request")
+	}
 	return nil
 }
 
+// rules merges the requested overrides into the default rules.
+func (req *CreateGameRequest) rules() domain.Rules {
+	rules := domain.DefaultRules()
+	if req.Rules == nil {
+		return rules
+	}
+	if req.Rules.DrawMode != nil {
+		rules.Mode = domain.DrawMode(*req.Rules.DrawMode)
+	}
+	if req.Rules.JokerProbability != nil {
+		rules.JokerProbability = *req.Rules.JokerProbability
+	}
+	if req.Rules.PityDraws != nil {
+		rules.PityDraws = *req.Rules.PityDraws
+	}
+	if req.Rules.PileSafeCards != nil {
+		rules.PileSafe = *req.Rules.PileSafeCards
+	}
+	if req.Rules.PileJokers != nil {
+		rules.PileJokers = *req.Rules.PileJokers
+	}
+	return rules
+}
+
+// timer merges the requested overrides into the default timer.
+func (req *CreateGameRequest) timer() domain.Timer {
+	timer := domain.DefaultTimer()
+	if req.Timer == nil {
+		return timer
+	}
+	if secs := req.Timer.AnswerTimeLimitSeconds; secs != nil {
+		// 先擋掉過大的值，避免換算 Duration 時溢位
+		timer.Limit = time.Duration(min(*secs, int(domain.MaxAnswerTimeLimit/time.Second)+1)) * time.Second
+	}
+	if req.Timer.TimeoutAction != nil {
+		timer.Action = domain.TimeoutAction(*req.Timer.TimeoutAction)
+	}
+	return timer
+}
+
+// scoring merges the requested overrides into the default scoring.
+func (req *CreateGameRequest) scoring() domain.Scoring {
+	scoring := domain.DefaultScoring()
+	if req.Scoring == nil {
+		return scoring
+	}
+	if req.Scoring.Safe != nil {
+		scoring.Safe = *req.Scoring.Safe
+	}
+	if req.Scoring.Joker != nil {
+		scoring.Joker = *req.Scoring.Joker
+	}
+	if req.Scoring.Skipped != nil {
+		scoring.Skipped = *req.Scoring.Skipped
+	}
+	if req.Scoring.TimedOut != nil {
+		scoring.TimedOut = *req.Scoring.TimedOut
+	}
+	return scoring
+}
+
+func (req *CreateGameRequest) voteRule() domain.VoteRule {
+	if req.VoteRule == nil {
+		return domain.VoteOff
+	}
+	return domain.VoteRule(*req.VoteRule)
+}
+
+func (req *CreateGameRequest) turnOrder() domain.TurnOrder {
+	if req.TurnOrder == nil {
+		return domain.TurnSequential
+	}
+	return domain.TurnOrder(*req.TurnOrder)
+}
+
+func rulesFromGame(game database.Game) domain.Rules {
+	return domain.Rules{
+		Mode:             domain.DrawMode(game.DrawMode),
+		JokerProbability: game.JokerProbability,
+		PityDraws:        int(game.PityDraws),
+		PileSafe:         int(game.PileSafeCards),
+		PileJokers:       int(game.PileJokers),
+	}
+}
+
+func drawStateFromGame(game database.Game) domain.DrawState {
+	return domain.DrawState{
+		SinceJoker: int(game.DrawsSinceJoker),
+		SafeLeft:   int(game.PileSafeLeft),
+		JokersLeft: int(game.PileJokersLeft),
+	}
+}
+
+func toGameRules(rules domain.Rules) GameRules {
+	return GameRules{
+		DrawMode:         string(rules.Mode),
+		JokerProbability: rules.JokerProbability,
+		PityDraws:        rules.PityDraws,
+		PileSafeCards:    rules.PileSafe,
+		PileJokers:       rules.PileJokers,
+	}
+}
+
+func timerFromGame(game database.Game) domain.Timer {
+	return domain.Timer{
+		Limit:  time.Duration(game.AnswerTimeLimitSeconds) * time.Second,
+		Action: domain.TimeoutAction(game.TimeoutAction),
+	}
+}
+
+func toGameTimer(timer domain.Timer) GameTimer {
+	return GameTimer{
+		AnswerTimeLimitSeconds: int(timer.Limit / time.Second),
+		TimeoutAction:          string(timer.Action),
+	}
+}
+
+func scoringFromGame(game database.Game) domain.Scoring {
+	return domain.Scoring{
+		Safe:     int(game.PointsSafe),
+		Joker:    int(game.PointsJoker),
+		Skipped:  int(game.PointsSkipped),
+		TimedOut: int(game.PointsTimedOut),
+	}
+}
+
+func toGameScoring(scoring domain.Scoring) GameScoring {
+	return GameScoring{
+		Safe:     scoring.Safe,
+		Joker:    scoring.Joker,
+		Skipped:  scoring.Skipped,
+		TimedOut: scoring.TimedOut,
+	}
+}
+
+// validateCustomQuestions checks the deck options and every inline question
+// against the questions schema, trimming them in place.
+func validateCustomQuestions(req *CreateGameRequest) map[string]string {
+	errs := map[string]string{}
+	if req.DeckID != nil && len(req.Questions) > 0 {
+		errs["deckId"] = "cannot be combined with questions"
+	}
+	if len(req.Questions) > maxInlineQuestions {
+		errs["questions"] = fmt.Sprintf("must have at most %d items", maxInlineQuestions)
+		return errs
+	}
+
+	for i, content := range req.Questions {
+		row := questions.Clean(questions.Row{Level: req.Level, Content: content})
+		if msg, ok := questions.Validate(row)["content"]; ok {
+			errs[fmt.Sprintf("questions[%d]", i)] = msg
+		}
+		req.Questions[i] = row.Content
+	}
+	return errs
+}
+
 func handleGameCodeError(c *gin.Context, logger *slog.Logger, err error) {
-	logger.Error("generate unique game code error: ", err)
+	logger.Error("generate unique game code error", "error", err)
 	if errors.Is(err, utils.ErrGenerateCode) {
 		InternalServerError(c, "code collision, try again")
 	} else {
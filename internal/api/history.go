@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+)
+
+// HistoryEntry is one round of a game and its drawer. Question is only set when a joker
+// revealed it or the viewer was the one answering it; IsJoker, Outcome,
+// Points and EndedAt stay nil while the round is in progress.
+type HistoryEntry struct {
+	RoundID   int64      `json:"roundId"`
+	Number    int        `json:"number"`
+	PlayerID  int64      `json:"playerId"`
+	Nickname  string     `json:"nickname"`
+	Status    string     `json:"status"`
+	IsJoker   *bool      `json:"isJoker"`
+	Outcome   *string    `json:"outcome"`
+	Points    *int       `json:"points"`
+	Attempts  int32      `json:"attempts"`
+	Penalty   bool       `json:"penalty"`
+	Question  *string    `json:"question"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+}
+
+type HistoryResponse struct {
+	Code     string         `json:"code"`
+	Status   string         `json:"status"`
+	Rounds   []HistoryEntry `json:"rounds"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"pageSize"`
+}
+
+// GetHistory lists the rounds of a game in the order they were played.
+func (h *StateHandler) GetHistory(c *gin.Context) {
+	ctx := c.Request.Context()
+	player := playerFrom(c)
+
+	errs := map[string]string{}
+	page, ok := queryInt(c, "page", 1)
+	if !ok || page < 1 {
+		errs["page"] = "must be a positive integer"
+	}
+	pageSize, ok := queryInt(c, "pageSize", defaultPageSize)
+	if !ok || pageSize < 1 || pageSize > maxPageSize {
+		errs["pageSize"] = "must be between 1 and 100"
+	}
+	if len(errs) > 0 {
+		FailedValidation(c, errs)
+		return
+	}
+
+	resp, err := h.history(ctx, c.Param("code"), player.ID, page, pageSize)
+	if err != nil {
+		respondError(c, h.logger, err, "failed to load game history")
+		return
+	}
+
+	Success(c, resp)
+}
+
+func (h *StateHandler) history(ctx context.Context, gameCode string, viewerID int64, page, pageSize int) (HistoryResponse, error) {
+	resp := HistoryResponse{
+		Rounds:   []HistoryEntry{},
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	game, err := h.store.GetGameByCode(ctx, gameCode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return resp, errGameNotFound
+		}
+		return resp, err
+	}
+	resp.Code, resp.Status = game.Code, game.Status
+
+	resp.Total, err = h.store.CountRoundsInGame(ctx, game.ID)
+	if err != nil {
+		return resp, err
+	}
+
+	offset := (page - 1) * pageSize
+	rounds, err := h.store.ListRoundHistory(ctx, database.ListRoundHistoryParams{
+		GameID: game.ID,
+		Offset: int32(offset),
+		Limit:  int32(pageSize),
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	for i, r := range rounds {
+		resp.Rounds = append(resp.Rounds, toHistoryEntry(r, offset+i+1, viewerID))
+	}
+	return resp, nil
+}
+
+func toHistoryEntry(r database.ListRoundHistoryRow, number int, viewerID int64) HistoryEntry {
+	entry := HistoryEntry{
+		RoundID:   r.ID,
+		Number:    number,
+		PlayerID:  r.CurrentPlayerID,
+		Nickname:  r.DrawerNickname,
+		Status:    r.Status,
+		Attempts:  r.Attempt,
+		Penalty:   r.Penalty,
+		StartedAt: r.CreatedAt.Time,
+	}
+
+	drawn := domain.RoundStatus(r.Status).IsDrawn()
+	if drawn {
+		entry.IsJoker = &r.IsJoker.Bool
+	}
+	// 題目只給翻出鬼牌時的所有人，或回答它的本人看
+	if (drawn && r.IsJoker.Bool) || r.CurrentPlayerID == viewerID {
+		entry.Question = &r.QuestionContent
+	}
+	if r.Outcome.Valid {
+		entry.Outcome = &r.Outcome.String
+		points := int(r.Points.Int32)
+		entry.Points = &points
+		entry.EndedAt = &r.EndedAt.Time
+	}
+	return entry
+}
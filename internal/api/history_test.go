@@ -0,0 +1,215 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/utils"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// historyRound is one round of a history test. A zero outcome leaves the
+// round in progress.
+type historyRound struct {
+	drawer  int
+	private bool
+	outcome domain.Outcome
+	joker   bool
+}
+
+// historyTestGame plays the given rounds, each on a question of its own,
+// and returns the players and the question of every round.
+func historyTestGame(t *testing.T, store database.Store, rounds []historyRound) (database.Game, []int64, []string) {
+	t.Helper()
+	ctx := context.Background()
+	game, rows := startTestGame(t, store, 2)
+	ids := []int64{rows[0].ID, rows[1].ID}
+
+	var contents []string
+	for i, r := range rounds {
+		content := game.Code + " global " + string(rune('a'+i))
+		var question database.Question
+		var err error
+		if r.private {
+			content = game.Code + " private " + string(rune('a'+i))
+			question, err = store.CreatePrivateQuestion(ctx, database.CreatePrivateQuestionParams{
+				Level:   game.Level,
+				Content: content,
+				GameID:  pgtype.Int8{Int64: game.ID, Valid: true},
+			})
+		} else {
+			question, err = store.CreateQuestion(ctx, database.CreateQuestionParams{Level: game.Level, Content: content})
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, content)
+
+		row, err := store.CreateRound(ctx, database.CreateRoundParams{
+			GameID:          game.ID,
+			QuestionID:      question.ID,
+			CurrentPlayerID: ids[r.drawer],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.outcome == "" {
+			continue
+		}
+		status := domain.RoundDone
+		if r.joker {
+			status = domain.RoundRevealed
+		}
+		if r.outcome == domain.OutcomeSkipped {
+			status = domain.RoundSkipped
+		}
+		err = store.UpdateRoundStatus(ctx, database.UpdateRoundStatusParams{
+			ID:      row.ID,
+			IsJoker: pgtype.Bool{Bool: r.joker, Valid: status != domain.RoundSkipped},
+			Status:  string(status),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		round := database.Round{ID: row.ID, GameID: game.ID, CurrentPlayerID: ids[r.drawer]}
+		if err := recordOutcome(ctx, store, game, round, r.outcome, r.joker); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return game, ids, contents
+}
+
+func newTestStateHandler(t *testing.T, store database.Store) *StateHandler {
+	t.Helper()
+	hub := ws.NewHub(ws.DefaultConfig(), ws.NewLocalBackplane())
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewStateHandler(store, logger, hub, utils.NewFakeClock(time.Unix(1_700_000_000, 0)))
+}
+
+func TestHistoryHidesQuestions(t *testing.T) {
+	rounds := []historyRound{
+		{drawer: 0, outcome: domain.OutcomeSafe},
+		{drawer: 1, outcome: domain.OutcomeJoker, joker: true},
+		{drawer: 0, private: true, outcome: domain.OutcomeSafe},
+		{drawer: 1, private: true, outcome: domain.OutcomeJoker, joker: true},
+		{drawer: 1, outcome: domain.OutcomeSkipped},
+		{drawer: 0, private: true},
+	}
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newTestStateHandler(t, store)
+			game, ids, contents := historyTestGame(t, store, rounds)
+
+			for viewer, viewerID := range ids {
+				resp, err := h.history(context.Background(), game.Code, viewerID, 1, defaultPageSize)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(resp.Rounds) != len(rounds) {
+					t.Fatalf("got %d rounds, want %d", len(resp.Rounds), len(rounds))
+				}
+				for i, entry := range resp.Rounds {
+					r := rounds[i]
+					// 翻出鬼牌的題目大家都看得到，其他的只有回答的人看得到
+					visible := r.joker || r.drawer == viewer
+					switch {
+					case visible && (entry.Question == nil || *entry.Question != contents[i]):
+						t.Errorf("viewer %d, round %d: question %v, want %q", viewer, i+1, entry.Question, contents[i])
+					case !visible && entry.Question != nil:
+						t.Errorf("viewer %d, round %d: question %q leaked", viewer, i+1, *entry.Question)
+					}
+
+					// 還沒抽或被跳過的回合沒有牌
+					if drawn := r.outcome != "" && r.outcome != domain.OutcomeSkipped; (entry.IsJoker != nil) != drawn {
+						t.Errorf("round %d: isJoker %v with outcome %q", i+1, entry.IsJoker, r.outcome)
+					}
+					if (entry.Outcome == nil) != (r.outcome == "") || (entry.EndedAt == nil) != (r.outcome == "") {
+						t.Errorf("round %d: outcome %v, endedAt %v, want them set only once it ended", i+1, entry.Outcome, entry.EndedAt)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := testStores(t)["memory"]
+	h := newTestStateHandler(t, store)
+
+	var rounds []historyRound
+	for i := range 5 {
+		rounds = append(rounds, historyRound{drawer: i % 2, outcome: domain.OutcomeSafe})
+	}
+	game, ids, _ := historyTestGame(t, store, rounds)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		c.Params = gin.Params{{Key: "code", Value: game.Code}}
+		c.Set(playerKey, database.Player{ID: ids[0], GameID: game.ID})
+		h.GetHistory(c)
+		return w
+	}
+
+	tests := []struct {
+		query   string
+		numbers []int
+	}{
+		{"", []int{1, 2, 3, 4, 5}},
+		{"pageSize=2", []int{1, 2}},
+		{"page=2&pageSize=2", []int{3, 4}},
+		{"page=3&pageSize=2", []int{5}},
+		{"page=4&pageSize=2", nil},
+	}
+	for _, tt := range tests {
+		w := get(tt.query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: status %d: %s", tt.query, w.Code, w.Body)
+		}
+		var resp struct {
+			Data HistoryResponse `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Data.Total != 5 || resp.Data.Code != game.Code {
+			t.Errorf("%q: total %d, code %q", tt.query, resp.Data.Total, resp.Data.Code)
+		}
+		if resp.Data.Rounds == nil {
+			t.Errorf("%q: rounds is null, want a list", tt.query)
+		}
+		var numbers []int
+		for _, entry := range resp.Data.Rounds {
+			numbers = append(numbers, entry.Number)
+		}
+		if len(numbers) != len(tt.numbers) {
+			t.Fatalf("%q: rounds %v, want %v", tt.query, numbers, tt.numbers)
+		}
+		for i := range numbers {
+			if numbers[i] != tt.numbers[i] {
+				t.Fatalf("%q: rounds %v, want %v", tt.query, numbers, tt.numbers)
+			}
+		}
+	}
+
+	for _, query := range []string{"page=0", "page=x", "pageSize=0", "pageSize=101"} {
+		if w := get(query); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%q: status %d, want 422", query, w.Code)
+		}
+	}
+}
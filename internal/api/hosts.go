@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// hostDisconnectGrace is how long a host can go without any replica seeing
+// them before the game gets a new host. It is measured from the host's last
+// last_seen_at stamp, so it holds across replicas and restarts.
+const hostDisconnectGrace = 30 * time.Second
+
+// presenceInterval is how often each replica stamps last_seen_at for the
+// players connected to it. It is well under hostDisconnectGrace, so a host
+// who came back through another replica is seen within the grace period.
+const presenceInterval = hostDisconnectGrace / 3
+
+// presenceTTL is how long a last_seen_at stamp counts as connected. It spans
+// two stamps so a single late one does not make a player look gone.
+const presenceTTL = 2 * presenceInterval
+
+// seenSince reports whether a replica stamped the player at or after cutoff.
+func seenSince(seen pgtype.Timestamptz, cutoff time.Time) bool {
+	return seen.Valid && !seen.Time.Before(cutoff)
+}
+
+// Reasons sent with host_changed.
+const (
+	hostTransferred = "transferred"
+	hostRemoved     = "removed"
+	hostLeft        = "disconnected"
+)
+
+type TransferHostRequest struct {
+	PlayerID int64 `json:"playerId" binding:"required"`
+}
+
+// HostChange is the payload of host_changed.
+type HostChange struct {
+	PreviousHostID int64  `json:"previousHostId"`
+	HostID         int64  `json:"hostId"`
+	Reason         string `json:"reason"`
+}
+
+// TransferHost lets the host hand the game to another player.
+func (h *RoundsHandler) TransferHost(c *gin.Context) {
+	ctx := c.Request.Context()
+	player := playerFrom(c)
+
+	var req TransferHostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "playerId is required")
+		return
+	}
+
+	change, err := h.transferHost(ctx, c.Param("code"), player.ID, req.PlayerID)
+	if err != nil {
+		respondError(c, h.logger, err, "failed to transfer host")
+		return
+	}
+
+	Success(c, change)
+}
+
+func (h *RoundsHandler) transferHost(ctx context.Context, gameCode string, hostID, playerID int64) (HostChange, error) {
+	change := HostChange{PreviousHostID: hostID, HostID: playerID, Reason: hostTransferred}
+
+	var game database.Game
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, err = lockGame(ctx, q, gameCode)
+		if err != nil {
+			return err
+		}
+
+		if _, err := activePlayer(ctx, q, game.ID, playerID); err != nil {
+			return err
+		}
+
+		return q.SetGameHost(ctx, database.SetGameHostParams{
+			GameID: game.ID,
+			ID:     playerID,
+		})
+	})
+	if err != nil {
+		return change, err
+	}
+
+	if hostID != playerID {
+		h.announceHost(game.Code, change)
+	}
+	return change, nil
+}
+
+// markOnline stamps last_seen_at for the players connected to this replica.
+// now is taken before asking the hub, so a stamp never postdates a
+// disconnect it did not see.
+func (h *RoundsHandler) markOnline(ctx context.Context, now time.Time) {
+	online := h.hub.OnlinePlayers()
+	if len(online) == 0 {
+		return
+	}
+
+	ids := make([]int64, 0, len(online))
+	for id := range online {
+		ids = append(ids, id)
+	}
+	err := h.store.TouchPlayers(ctx, database.TouchPlayersParams{
+		SeenAt: pgtype.Timestamptz{Time: now, Valid: true},
+		Ids:    ids,
+	})
+	if err != nil {
+		h.logger.Error("mark players online failed", "error", err)
+	}
+}
+
+// replaceHosts promotes a new host in every game whose host no replica has
+// stamped within hostDisconnectGrace. A host connected here or through
+// another replica keeps being stamped and keeps the role.
+func (h *RoundsHandler) replaceHosts(ctx context.Context, now time.Time) {
+	cutoff := now.Add(-hostDisconnectGrace)
+	absent, err := h.store.ListAbsentHosts(ctx, pgtype.Timestamptz{Time: cutoff, Valid: true})
+	if err != nil {
+		h.logger.Error("list absent hosts failed", "error", err)
+		return
+	}
+
+	for _, gone := range absent {
+		code, hostID := gone.Code, gone.ID
+		if h.hub.ConnectedPlayers(code)[hostID] {
+			continue
+		}
+
+		var (
+			change HostChange
+			ok     bool
+		)
+		err := h.store.ExecTx(ctx, func(q database.Store) error {
+			game, err := lockGame(ctx, q, code)
+			if err != nil {
+				return err
+			}
+			if domain.GameStatus(game.Status) == domain.GameEnded {
+				return nil
+			}
+
+			host, err := q.GetPlayerInGame(ctx, database.GetPlayerInGameParams{
+				ID:     hostID,
+				GameID: game.ID,
+			})
+			if err != nil || !host.IsHost.Bool {
+				// 已經被踢掉或把主持人轉給別人了
+				if errors.Is(err, sql.ErrNoRows) {
+					err = nil
+				}
+				return err
+			}
+			// 鎖住之後再看一次，別台 replica 可能剛幫他蓋過章
+			if seenSince(host.LastSeenAt, cutoff) {
+				return nil
+			}
+
+			change, ok, err = promoteHost(ctx, q, game, hostID)
+			return err
+		})
+		if err != nil {
+			h.logger.Error("replace disconnected host failed", "game", code, "error", err)
+			continue
+		}
+		if ok {
+			change.Reason = hostLeft
+			h.announceHost(code, change)
+		}
+	}
+}
+
+// promoteHost makes the earliest-joined player other than leaving the host,
+// whether or not they are connected. ok is false when nobody is left.
+func promoteHost(ctx context.Context, q database.Store, game database.Game, leaving int64) (HostChange, bool, error) {
+	change := HostChange{PreviousHostID: leaving}
+
+	players, err := q.ListPlayersByGameCode(ctx, game.Code)
+	if err != nil {
+		return change, false, err
+	}
+	// 名單依座位排序，主持人要照加入時間交接
+	var next *database.ListPlayersByGameCodeRow
+	for i, p := range players {
+		if p.ID == leaving {
+			continue
+		}
+		if next == nil || joinedBefore(p, *next) {
+			next = &players[i]
+		}
+	}
+	if next != nil {
+		change.HostID = next.ID
+	}
+	if change.HostID == 0 {
+		return change, false, nil
+	}
+
+	err = q.SetGameHost(ctx, database.SetGameHostParams{
+		GameID: game.ID,
+		ID:     change.HostID,
+	})
+	return change, err == nil, err
+}
+
+// joinedBefore orders players by joined_at, then by ID for players who
+// joined at the same instant.
+func joinedBefore(a, b database.ListPlayersByGameCodeRow) bool {
+	if !a.JoinedAt.Time.Equal(b.JoinedAt.Time) {
+		return a.JoinedAt.Time.Before(b.JoinedAt.Time)
+	}
+	return a.ID < b.ID
+}
+
+func (h *RoundsHandler) announceHost(gameCode string, change HostChange) {
+	// 👑 廣播主持人變更
+	h.hub.BroadcastToGame(gameCode, ws.WebSocketMessage{
+		Type: "host_changed",
+		Data: change,
+	})
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/utils"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// hostTestGame creates a lobby whose players joined a second apart, then
+// reverses their seats so seat order and join order disagree.
+func hostTestGame(t *testing.T, store database.Store, clock *utils.FakeClock, players int) (database.Game, []int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	game, _ := startTestGameWith(t, store, func(p *database.CreateGameParams) {
+		p.Status = "waiting"
+	}, 0)
+
+	var ids []int64
+	var seats []int32
+	for i := range players {
+		row, err := store.CreatePlayer(ctx, database.CreatePlayerParams{
+			GameID:   game.ID,
+			Nickname: fmt.Sprintf("p%d", i),
+			IsHost:   pgtype.Bool{Bool: i == 0, Valid: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, row.ID)
+		seats = append([]int32{row.Seat}, seats...)
+		clock.Advance(time.Second)
+	}
+	err := store.UpdatePlayerSeats(ctx, database.UpdatePlayerSeatsParams{GameID: game.ID, Ids: ids, Seats: seats})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return game, ids
+}
+
+func currentHost(t *testing.T, store database.Store, game database.Game) int64 {
+	t.Helper()
+	players, err := store.ListPlayersByGameCode(context.Background(), game.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range players {
+		if p.IsHost.Bool {
+			return p.ID
+		}
+	}
+	return 0
+}
+
+func TestPromoteHostByJoinedAt(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(1_700_000_000, 0))
+	store := memory.New(clock)
+	h := newTestRoundsHandler(t, store)
+	game, ids := hostTestGame(t, store, clock, 4)
+
+	// 座位最前面的是最後加入的人，接手的要是第二個加入的
+	if err := h.removePlayer(context.Background(), game.Code, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := currentHost(t, store, game); got != ids[1] {
+		t.Fatalf("host is %d, want %d (earliest joined)", got, ids[1])
+	}
+}
+
+func TestReplaceHostConnectedElsewhere(t *testing.T) {
+	ctx := context.Background()
+	clock := utils.NewFakeClock(time.Unix(1_700_000_000, 0))
+	store := memory.New(clock)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	bp := ws.NewLocalBackplane()
+	var replicas []*RoundsHandler
+	for range 2 {
+		hub := ws.NewHub(ws.DefaultConfig(), bp)
+		go hub.Run()
+		t.Cleanup(hub.Stop)
+		replicas = append(replicas, NewRoundsHandler(store, logger, hub, utils.NewSeededRandom(1), clock))
+	}
+	a, b := replicas[0], replicas[1]
+
+	game, ids := hostTestGame(t, store, clock, 3)
+	host := ids[0]
+	join := func(h *RoundsHandler) *ws.Client {
+		client := &ws.Client{Send: make(chan []byte, 64), GameCode: game.Code, PlayerID: host, Hub: h.hub}
+		h.hub.Register(client)
+		return client
+	}
+	leave := func(h *RoundsHandler, client *ws.Client) {
+		h.hub.Unregister(client)
+		for h.hub.ConnectedPlayers(game.Code)[host] {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// 從沒連上 socket 的主持人沒有 last_seen_at，不會被換掉
+	clock.Advance(hostDisconnectGrace)
+	a.replaceHosts(ctx, clock.Now())
+	if got := currentHost(t, store, game); got != host {
+		t.Fatalf("host who never connected was replaced by %d", got)
+	}
+
+	// 主持人在 a 斷線，但已經從 b 連回來
+	onA := join(a)
+	a.markOnline(ctx, clock.Now())
+	leave(a, onA)
+	onB := join(b)
+	clock.Advance(presenceInterval)
+	b.markOnline(ctx, clock.Now())
+
+	clock.Advance(hostDisconnectGrace)
+	a.replaceHosts(ctx, clock.Now())
+	if got := currentHost(t, store, game); got != host {
+		t.Fatalf("host connected through b was replaced by %d", got)
+	}
+
+	// 沒有任何 replica 再看到他，就換第二個加入的人；不需要看過他斷線的那台
+	b.markOnline(ctx, clock.Now())
+	leave(b, onB)
+	clock.Advance(presenceInterval)
+	b.markOnline(ctx, clock.Now())
+	a.replaceHosts(ctx, clock.Now())
+	if got := currentHost(t, store, game); got != host {
+		t.Fatalf("host was replaced by %d before the grace period ended", got)
+	}
+	clock.Advance(hostDisconnectGrace)
+	restarted := NewRoundsHandler(store, logger, a.hub, utils.NewSeededRandom(1), clock)
+	restarted.replaceHosts(ctx, clock.Now())
+	if got := currentHost(t, store, game); got != ids[1] {
+		t.Fatalf("host is %d, want %d", got, ids[1])
+	}
+}
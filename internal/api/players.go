@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log/slog"
@@ -8,22 +9,26 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/auth"
 	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
 	"github.com/y3933y3933/joker/internal/utils"
 	"github.com/y3933y3933/joker/internal/ws"
 )
 
 type PlayersHandler struct {
-	logger  *slog.Logger
-	queries *database.Queries
-	hub     *ws.Hub
+	logger *slog.Logger
+	store  database.Store
+	hub    *ws.Hub
+	tokens *auth.TokenManager
 }
 
-func NewPlayersHandler(queries *database.Queries, logger *slog.Logger, hub *ws.Hub) *PlayersHandler {
+func NewPlayersHandler(store database.Store, logger *slog.Logger, hub *ws.Hub, tokens *auth.TokenManager) *PlayersHandler {
 	return &PlayersHandler{
-		logger:  logger,
-		queries: queries,
-		hub:     hub,
+		logger: logger,
+		store:  store,
+		hub:    hub,
+		tokens: tokens,
 	}
 }
 
@@ -31,15 +36,16 @@ type PlayerResponse struct {
 	ID       int64  `json:"id"`
 	Nickname string `json:"nickname"`
 	IsHost   bool   `json:"isHost"`
+	Seat     int32  `json:"seat"`
 }
 
 func (h *PlayersHandler) ListPlayers(c *gin.Context) {
 	ctx := c.Request.Context()
 	code := c.Param("code")
 
-	players, err := h.queries.ListPlayersByGameCode(ctx, code)
+	players, err := h.store.ListPlayersByGameCode(ctx, code)
 	if err != nil {
-		h.logger.Error("list players by game code error: ", err)
+		h.logger.Error("list players by game code error", "error", err)
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			BadRequest(c, "game not found")
@@ -59,6 +65,7 @@ func transformToPlayerResponse(players []database.ListPlayersByGameCodeRow) []Pl
 			ID:       p.ID,
 			Nickname: p.Nickname,
 			IsHost:   p.IsHost.Bool,
+			Seat:     p.Seat,
 		})
 	}
 	return playerResponses
@@ -68,6 +75,11 @@ type JoinGameRequest struct {
 	Nickname string `json:"nickname" binding:"required"`
 }
 
+type JoinGameResponse struct {
+	PlayerResponse
+	Token string `json:"token"`
+}
+
 func (h *PlayersHandler) JoinGame(c *gin.Context) {
 	ctx := c.Request.Context()
 	gameCode := c.Param("code")
@@ -79,30 +91,44 @@ func (h *PlayersHandler) JoinGame(c *gin.Context) {
 		return
 	}
 
-	game, err := h.queries.GetGameByCode(ctx, gameCode)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			NotFound(c, "game not found")
-		} else {
-			InternalServerError(c, "DB error")
+	var (
+		game   database.Game
+		player database.CreatePlayerRow
+	)
+	// 在 game 鎖之內計算人數，避免同時加入時產生兩位主持人
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, err = lockGame(ctx, q, gameCode)
+		if err != nil {
+			return err
 		}
-		return
-	}
 
-	count, err := h.queries.CountPlayersInGame(ctx, game.ID)
+		if err := domain.CanJoin(domain.GameStatus(game.Status)); err != nil {
+			return err
+		}
+
+		count, err := q.CountPlayersInGame(ctx, game.ID)
+		if err != nil {
+			return err
+		}
+		isHost := count == 0
+
+		player, err = q.CreatePlayer(ctx, database.CreatePlayerParams{
+			GameID:   game.ID,
+			Nickname: req.Nickname,
+			IsHost:   pgtype.Bool{Bool: isHost, Valid: true},
+		})
+		return err
+	})
 	if err != nil {
-		InternalServerError(c, "Count error")
+		respondError(c, h.logger, err, "Create player failed")
 		return
 	}
-	isHost := count == 0
 
-	player, err := h.queries.CreatePlayer(ctx, database.CreatePlayerParams{
-		GameID:   game.ID,
-		Nickname: req.Nickname,
-		IsHost:   pgtype.Bool{Bool: isHost, Valid: true},
-	})
+	token, err := h.tokens.Issue(player.ID, game.ID, game.Code)
 	if err != nil {
-		InternalServerError(c, "Create player failed")
+		h.logger.Error("issue session token error", "error", err)
+		InternalServerError(c, "failed to issue session token")
 		return
 	}
 
@@ -113,14 +139,19 @@ func (h *PlayersHandler) JoinGame(c *gin.Context) {
 			"id":       player.ID,
 			"nickname": player.Nickname,
 			"isHost":   player.IsHost,
+			"seat":     player.Seat,
 		},
 	})
 
-	// ✅ 回傳該玩家資訊
-	Success(c, PlayerResponse{
-		ID:       player.ID,
-		Nickname: player.Nickname,
-		IsHost:   player.IsHost.Bool,
+	// ✅ 回傳該玩家資訊與 session token
+	Success(c, JoinGameResponse{
+		PlayerResponse: PlayerResponse{
+			ID:       player.ID,
+			Nickname: player.Nickname,
+			IsHost:   player.IsHost.Bool,
+			Seat:     player.Seat,
+		},
+		Token: token,
 	})
 
 }
@@ -136,23 +167,95 @@ func (h *RoundsHandler) RemovePlayer(c *gin.Context) {
 		return
 	}
 
-	game, err := h.queries.GetGameByCode(ctx, gameCode)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			NotFound(c, "game not found")
-			return
-		}
-		InternalServerError(c, "db error")
+	if err := h.removePlayer(ctx, gameCode, playerID); err != nil {
+		respondError(c, h.logger, err, "failed to remove player")
 		return
 	}
 
-	err = h.queries.DeletePlayer(ctx, database.DeletePlayerParams{
-		ID:     playerID,
-		GameID: game.ID,
+	c.Status(http.StatusNoContent)
+}
+
+// removePlayer takes the player out of the game. The player keeps their
+// seat, rounds and votes, but no longer takes turns or counts as a voter. A
+// round the player was drawing is skipped, a vote in progress is counted
+// again without them, and a removed host hands the game to the next player.
+func (h *RoundsHandler) removePlayer(ctx context.Context, gameCode string, playerID int64) error {
+	var (
+		game     database.Game
+		left     bool
+		change   HostChange
+		promoted bool
+		round    database.Round
+		skipped  bool
+		vote     *VoteResult
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, err = lockGame(ctx, q, gameCode)
+		if err != nil {
+			return err
+		}
+
+		player, err := q.GetPlayerInGame(ctx, database.GetPlayerInGameParams{
+			ID:     playerID,
+			GameID: game.ID,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		if player.LeftAt.Valid {
+			return nil
+		}
+
+		err = q.MarkPlayerLeft(ctx, database.MarkPlayerLeftParams{
+			ID:     playerID,
+			GameID: game.ID,
+			LeftAt: pgtype.Timestamptz{Time: h.clock.Now(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		left = true
+
+		if player.IsHost.Bool {
+			change, promoted, err = promoteHost(ctx, q, game, playerID)
+			if err != nil {
+				return err
+			}
+		}
+
+		if domain.CanPlay(domain.GameStatus(game.Status)) != nil {
+			return nil
+		}
+		round, err = q.GetLatestRoundInGame(ctx, game.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		// 輪到的人離開就跳過這回合，投票中則不算他的票重新計票
+		status := domain.RoundStatus(round.Status)
+		switch {
+		case status.IsFinal():
+		case round.CurrentPlayerID == playerID:
+			skipped = true
+			return markSkipped(ctx, q, game, round)
+		case status == domain.RoundVoting:
+			result, err := h.tally(ctx, q, game, &round)
+			if err != nil {
+				return err
+			}
+			vote = &result
+		}
+		return nil
 	})
-	if err != nil {
-		InternalServerError(c, "failed to remove player")
-		return
+	if err != nil || !left {
+		return err
 	}
 
 	// 廣播玩家離開
@@ -162,6 +265,17 @@ func (h *RoundsHandler) RemovePlayer(c *gin.Context) {
 			"id": playerID,
 		},
 	})
-
-	c.Status(http.StatusNoContent)
+	// 收到 player_left 後就關掉他的連線，之後的訊息不再送給他
+	h.hub.DisconnectPlayer(game.Code, playerID)
+	if promoted {
+		change.Reason = hostRemoved
+		h.announceHost(game.Code, change)
+	}
+	if skipped {
+		h.announceSkipped(ctx, game, round)
+	}
+	if vote != nil {
+		h.announceTally(game, round, *vote)
+	}
+	return nil
 }
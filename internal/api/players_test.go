@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+func TestRemovePlayerStampsClock(t *testing.T) {
+	ctx := context.Background()
+	clock := utils.NewFakeClock(time.Unix(1_700_000_000, 0))
+	store := memory.New(utils.SystemClock{})
+	h := newTestRoundsHandler(t, store)
+	h.clock = clock
+	game, players := startTestGame(t, store, 2)
+
+	clock.Advance(time.Hour)
+	if err := h.removePlayer(ctx, game.Code, players[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	player, err := store.GetPlayerInGame(ctx, database.GetPlayerInGameParams{ID: players[1].ID, GameID: game.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !player.LeftAt.Time.Equal(clock.Now()) {
+		t.Fatalf("left_at = %v, want the handler's clock %v", player.LeftAt.Time, clock.Now())
+	}
+}
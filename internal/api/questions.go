@@ -0,0 +1,332 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/questions"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	maxImportBytes  = 5 << 20
+)
+
+type QuestionsHandler struct {
+	logger *slog.Logger
+	store  database.Store
+	clock  utils.Clock
+}
+
+func NewQuestionsHandler(store database.Store, logger *slog.Logger, clock utils.Clock) *QuestionsHandler {
+	return &QuestionsHandler{
+		logger: logger,
+		store:  store,
+		clock:  clock,
+	}
+}
+
+type QuestionRequest struct {
+	Level   string   `json:"level"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+}
+
+type QuestionResponse struct {
+	ID        int64     `json:"id"`
+	Level     string    `json:"level"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type QuestionListResponse struct {
+	Questions []QuestionResponse `json:"questions"`
+	Total     int64              `json:"total"`
+	Page      int                `json:"page"`
+	PageSize  int                `json:"pageSize"`
+}
+
+func (h *QuestionsHandler) ListQuestions(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	level := c.Query("level")
+	search := strings.TrimSpace(c.Query("q"))
+
+	errs := map[string]string{}
+	if level != "" && !questions.IsLevel(level) {
+		errs["level"] = "must be one of easy, normal, spicy"
+	}
+	page, ok := queryInt(c, "page", 1)
+	if !ok || page < 1 {
+		errs["page"] = "must be a positive integer"
+	}
+	pageSize, ok := queryInt(c, "pageSize", defaultPageSize)
+	if !ok || pageSize < 1 || pageSize > maxPageSize {
+		errs["pageSize"] = "must be between 1 and 100"
+	}
+	if len(errs) > 0 {
+		FailedValidation(c, errs)
+		return
+	}
+
+	levelFilter := pgtype.Text{String: level, Valid: level != ""}
+	searchFilter := pgtype.Text{String: search, Valid: search != ""}
+
+	total, err := h.store.CountQuestions(ctx, database.CountQuestionsParams{
+		Level:  levelFilter,
+		Search: searchFilter,
+	})
+	if err != nil {
+		h.logger.Error("count questions failed", "error", err)
+		InternalServerError(c, "failed to list questions")
+		return
+	}
+
+	list, err := h.store.ListQuestions(ctx, database.ListQuestionsParams{
+		Level:  levelFilter,
+		Search: searchFilter,
+		Offset: int32((page - 1) * pageSize),
+		Limit:  int32(pageSize),
+	})
+	if err != nil {
+		h.logger.Error("list questions failed", "error", err)
+		InternalServerError(c, "failed to list questions")
+		return
+	}
+
+	resp := QuestionListResponse{
+		Questions: make([]QuestionResponse, 0, len(list)),
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
+	}
+	for _, q := range list {
+		resp.Questions = append(resp.Questions, toQuestionResponse(q))
+	}
+	Success(c, resp)
+}
+
+func (h *QuestionsHandler) GetQuestion(c *gin.Context) {
+	id, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid question id")
+		return
+	}
+
+	question, err := h.store.GetActiveQuestion(c.Request.Context(), id)
+	if err != nil {
+		h.handleQuestionError(c, err, "failed to get question")
+		return
+	}
+
+	Success(c, toQuestionResponse(question))
+}
+
+func (h *QuestionsHandler) CreateQuestion(c *gin.Context) {
+	var req QuestionRequest
+	if !bindQuestionRequest(c, &req) {
+		return
+	}
+
+	question, err := h.store.CreateQuestion(c.Request.Context(), database.CreateQuestionParams{
+		Level:   req.Level,
+		Content: req.Content,
+		Tags:    req.Tags,
+	})
+	if err != nil {
+		h.handleQuestionError(c, err, "failed to create question")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "success",
+		"data":    toQuestionResponse(question),
+	})
+}
+
+func (h *QuestionsHandler) UpdateQuestion(c *gin.Context) {
+	id, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid question id")
+		return
+	}
+
+	var req QuestionRequest
+	if !bindQuestionRequest(c, &req) {
+		return
+	}
+
+	question, err := h.store.UpdateQuestion(c.Request.Context(), database.UpdateQuestionParams{
+		ID:        id,
+		Level:     req.Level,
+		Content:   req.Content,
+		Tags:      req.Tags,
+		UpdatedAt: pgtype.Timestamptz{Time: h.clock.Now(), Valid: true},
+	})
+	if err != nil {
+		h.handleQuestionError(c, err, "failed to update question")
+		return
+	}
+
+	Success(c, toQuestionResponse(question))
+}
+
+// DeleteQuestion soft-deletes a question: it stops being drawn but rounds
+// that already used it keep their content.
+func (h *QuestionsHandler) DeleteQuestion(c *gin.Context) {
+	id, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid question id")
+		return
+	}
+
+	deleted, err := h.store.SoftDeleteQuestion(c.Request.Context(), database.SoftDeleteQuestionParams{
+		ID:        id,
+		DeletedAt: pgtype.Timestamptz{Time: h.clock.Now(), Valid: true},
+	})
+	if err != nil {
+		h.logger.Error("delete question failed", "error", err)
+		InternalServerError(c, "failed to delete question")
+		return
+	}
+	if deleted == 0 {
+		NotFound(c, "question not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *QuestionsHandler) handleQuestionError(c *gin.Context, err error, msg string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		NotFound(c, "question not found")
+		return
+	}
+	if isUniqueViolation(err) {
+		Conflict(c, "question already exists")
+		return
+	}
+	h.logger.Error(msg, "error", err)
+	InternalServerError(c, msg)
+}
+
+// bindQuestionRequest decodes the body and reports every invalid field at
+// once through FailedValidation.
+func bindQuestionRequest(c *gin.Context, req *QuestionRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		BadRequest(c, "Invalid request format")
+		return false
+	}
+
+	row := questions.Clean(questions.Row{Level: req.Level, Content: req.Content, Tags: req.Tags})
+	if errs := questions.Validate(row); len(errs) > 0 {
+		FailedValidation(c, errs)
+		return false
+	}
+	req.Level, req.Content, req.Tags = row.Level, row.Content, row.Tags
+	return true
+}
+
+// ImportQuestions loads a CSV or JSON file from the request body. Pass
+// ?dryRun=true to only get the report.
+func (h *QuestionsHandler) ImportQuestions(c *gin.Context) {
+	format, err := requestFormat(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+
+	rows, err := questions.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes), format)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := questions.Import(c.Request.Context(), h.store, rows, dryRun)
+	if err != nil {
+		if errors.Is(err, questions.ErrInvalidRows) {
+			FailedValidation(c, report)
+			return
+		}
+		h.logger.Error("import questions failed", "error", err)
+		InternalServerError(c, "failed to import questions")
+		return
+	}
+
+	Success(c, report)
+}
+
+// ExportQuestions returns the active questions in the format ImportQuestions
+// accepts.
+func (h *QuestionsHandler) ExportQuestions(c *gin.Context) {
+	format, err := questions.ParseFormat(c.DefaultQuery("format", string(questions.FormatJSON)))
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+	level := c.Query("level")
+	if level != "" && !questions.IsLevel(level) {
+		FailedValidation(c, map[string]string{"level": "must be one of easy, normal, spicy"})
+		return
+	}
+
+	rows, err := questions.Export(c.Request.Context(), h.store, level)
+	if err != nil {
+		h.logger.Error("export questions failed", "error", err)
+		InternalServerError(c, "failed to export questions")
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="questions.%s"`, format))
+	c.Status(http.StatusOK)
+	if err := questions.Encode(c.Writer, format, rows); err != nil {
+		h.logger.Error("encode questions failed", "error", err)
+	}
+}
+
+// requestFormat reads ?format=, falling back to the Content-Type.
+func requestFormat(c *gin.Context) (questions.Format, error) {
+	if f := c.Query("format"); f != "" {
+		return questions.ParseFormat(f)
+	}
+	if strings.HasPrefix(c.ContentType(), "text/csv") {
+		return questions.FormatCSV, nil
+	}
+	return questions.FormatJSON, nil
+}
+
+// queryInt reads an integer query param, returning def when it is absent.
+func queryInt(c *gin.Context, key string, def int) (int, bool) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(raw)
+	return n, err == nil
+}
+
+func toQuestionResponse(q database.Question) QuestionResponse {
+	return QuestionResponse{
+		ID:        q.ID,
+		Level:     q.Level,
+		Content:   q.Content,
+		Tags:      q.Tags,
+		CreatedAt: q.CreatedAt.Time,
+		UpdatedAt: q.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+func newTestQuestionsHandler(store database.Store) *QuestionsHandler {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewQuestionsHandler(store, logger, utils.NewFakeClock(time.Unix(1_700_000_000, 0)))
+}
+
+// callJSON runs a handler with an optional :id param and JSON body,
+// and decodes the data of a successful response into out, which may be nil.
+func callJSON(t *testing.T, handler gin.HandlerFunc, method, target, id string, body any, want int, out any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, reader)
+	c.Request.Header.Set("Content-Type", "application/json")
+	if id != "" {
+		c.Params = gin.Params{{Key: "id", Value: id}}
+	}
+	handler(c)
+	c.Writer.WriteHeaderNow()
+
+	if w.Code != want {
+		t.Fatalf("%s %s %s: status %d, want %d: %s", method, target, id, w.Code, want, w.Body)
+	}
+	if out == nil {
+		return
+	}
+	resp := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQuestionsCRUD(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newTestQuestionsHandler(store)
+			content := fmt.Sprintf("crud %d", time.Now().UnixNano())
+
+			var created QuestionResponse
+			callJSON(t, h.CreateQuestion, http.MethodPost, "/", "", gin.H{"level": " easy ", "content": "  " + content + "  ", "tags": []string{"party"}}, http.StatusCreated, &created)
+			if created.ID == 0 || created.Level != "easy" || created.Content != content {
+				t.Fatalf("created %+v, want a trimmed question", created)
+			}
+			id := fmt.Sprint(created.ID)
+
+			// 同等級的題目不分大小寫只能有一題
+			callJSON(t, h.CreateQuestion, http.MethodPost, "/", "", gin.H{"level": "easy", "content": strings.ToUpper(content)}, http.StatusConflict, nil)
+
+			// 欄位錯誤一次全部回報
+			var errs struct {
+				Details map[string]string `json:"details"`
+			}
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(`{"level":"mild","content":""}`)))
+			h.CreateQuestion(c)
+			if err := json.Unmarshal(w.Body.Bytes(), &errs); err != nil || w.Code != http.StatusUnprocessableEntity || len(errs.Details) != 2 {
+				t.Fatalf("status %d %s, want 422 for level and content", w.Code, w.Body)
+			}
+
+			var got QuestionResponse
+			callJSON(t, h.GetQuestion, http.MethodGet, "/", id, nil, http.StatusOK, &got)
+			if got.ID != created.ID || got.Content != content {
+				t.Fatalf("got %+v, want %+v", got, created)
+			}
+
+			var updated QuestionResponse
+			callJSON(t, h.UpdateQuestion, http.MethodPut, "/", id, gin.H{"level": "spicy", "content": content + " updated"}, http.StatusOK, &updated)
+			if updated.Level != "spicy" || updated.Content != content+" updated" || len(updated.Tags) != 0 {
+				t.Fatalf("updated %+v", updated)
+			}
+			callJSON(t, h.GetQuestion, http.MethodGet, "/", id, nil, http.StatusOK, &got)
+			if got.Level != "spicy" {
+				t.Fatalf("got %+v after update", got)
+			}
+
+			// 刪掉之後就找不到，也不能再改
+			callJSON(t, h.DeleteQuestion, http.MethodDelete, "/", id, nil, http.StatusNoContent, nil)
+			callJSON(t, h.GetQuestion, http.MethodGet, "/", id, nil, http.StatusNotFound, nil)
+			callJSON(t, h.UpdateQuestion, http.MethodPut, "/", id, gin.H{"level": "easy", "content": content}, http.StatusNotFound, nil)
+			callJSON(t, h.DeleteQuestion, http.MethodDelete, "/", id, nil, http.StatusNotFound, nil)
+			callJSON(t, h.GetQuestion, http.MethodGet, "/", "abc", nil, http.StatusBadRequest, nil)
+		})
+	}
+}
+
+func TestListQuestionsPages(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newTestQuestionsHandler(store)
+			token := fmt.Sprintf("list%d", time.Now().UnixNano())
+			contents := []string{
+				token + " 100% sure",
+				token + " 100 percent",
+				token + " a_b",
+				token + " axb",
+				token + ` back\slash`,
+				token + " backslash",
+			}
+			for i, content := range contents {
+				level := "easy"
+				if i%2 == 1 {
+					level = "spicy"
+				}
+				callJSON(t, h.CreateQuestion, http.MethodPost, "/", "", gin.H{"level": level, "content": content}, http.StatusCreated, nil)
+			}
+
+			list := func(query url.Values) QuestionListResponse {
+				t.Helper()
+				var resp QuestionListResponse
+				callJSON(t, h.ListQuestions, http.MethodGet, "/?"+query.Encode(), "", nil, http.StatusOK, &resp)
+				return resp
+			}
+			contentsOf := func(resp QuestionListResponse) []string {
+				var got []string
+				for _, q := range resp.Questions {
+					got = append(got, q.Content)
+				}
+				return got
+			}
+
+			// 分頁照 id 排，total 是全部符合的數量
+			var all []string
+			for page := 1; page <= 3; page++ {
+				resp := list(url.Values{"q": {token}, "page": {fmt.Sprint(page)}, "pageSize": {"4"}})
+				if resp.Total != 6 || resp.Page != page || resp.PageSize != 4 {
+					t.Fatalf("page %d: total %d, page %d, pageSize %d", page, resp.Total, resp.Page, resp.PageSize)
+				}
+				all = append(all, contentsOf(resp)...)
+			}
+			if fmt.Sprint(all) != fmt.Sprint(contents) {
+				t.Fatalf("pages gave %q, want %q", all, contents)
+			}
+
+			if resp := list(url.Values{"q": {token}, "level": {"spicy"}}); resp.Total != 3 {
+				t.Fatalf("spicy: %q", contentsOf(resp))
+			}
+
+			// 搜尋字串照字面比對，%、_ 和 \ 都不是萬用字元
+			tests := []struct {
+				search string
+				want   []string
+			}{
+				{token + " 100%", contents[:1]},
+				{token + " a_b", contents[2:3]},
+				{token + ` back\`, contents[4:5]},
+				{token + " 100% SURE", contents[:1]},
+			}
+			for _, tt := range tests {
+				resp := list(url.Values{"q": {tt.search}})
+				if got := contentsOf(resp); fmt.Sprint(got) != fmt.Sprint(tt.want) || resp.Total != int64(len(tt.want)) {
+					t.Errorf("search %q: got %q (total %d), want %q", tt.search, got, resp.Total, tt.want)
+				}
+			}
+
+			for _, query := range []string{"page=0", "pageSize=101", "level=mild"} {
+				callJSON(t, h.ListQuestions, http.MethodGet, "/?"+query, "", nil, http.StatusUnprocessableEntity, nil)
+			}
+		})
+	}
+}
@@ -1,31 +1,41 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 
-	"math/rand"
-
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/fairness"
 	"github.com/y3933y3933/joker/internal/utils"
 	"github.com/y3933y3933/joker/internal/ws"
 )
 
 type RoundsHandler struct {
-	logger  *slog.Logger
-	queries *database.Queries
-	hub     *ws.Hub
+	logger *slog.Logger
+	store  database.Store
+	hub    *ws.Hub
+	rng    utils.Random
+	clock  utils.Clock
+	timers *roundTimers
 }
 
-func NewRoundsHandler(queries *database.Queries, logger *slog.Logger, hub *ws.Hub) *RoundsHandler {
+func NewRoundsHandler(store database.Store, logger *slog.Logger, hub *ws.Hub, rng utils.Random, clock utils.Clock) *RoundsHandler {
 	return &RoundsHandler{
-		logger:  logger,
-		queries: queries,
-		hub:     hub,
+		logger: logger,
+		store:  store,
+		hub:    hub,
+		rng:    rng,
+		clock:  clock,
+		timers: newRoundTimers(),
 	}
 }
 
@@ -45,27 +55,20 @@ type CurrentRoundResponse struct {
 func (h *RoundsHandler) GetCurrentRound(c *gin.Context) {
 	ctx := c.Request.Context()
 	gameCode := c.Param("code")
+	player := playerFrom(c)
 
-	playerIDStr := c.Query("player_id")
-	playerID, err := utils.ParseID(playerIDStr)
-
-	if err != nil {
-		BadRequest(c, "invalid player id")
-		return
-	}
-
-	round, err := h.queries.GetCurrentRoundByGameCode(ctx, gameCode)
+	round, err := h.store.GetCurrentRoundByGameCode(ctx, gameCode)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			NotFound(c, "no round found for this game")
 		} else {
-			h.logger.Error("get current round failed", err)
+			h.logger.Error("get current round failed", "error", err)
 			InternalServerError(c, "failed to get current round")
 		}
 		return
 	}
 
-	if round.CurrentPlayerID != playerID {
+	if round.CurrentPlayerID != player.ID {
 		round.QuestionContent = ""
 	}
 
@@ -85,8 +88,9 @@ type CreateRoundRequest struct {
 }
 
 type CreateRoundResponse struct {
-	RoundID  int64 `json:"roundId"`
-	PlayerID int64 `json:"playerId"`
+	RoundID    int64  `json:"roundId"`
+	PlayerID   int64  `json:"playerId"`
+	Commitment string `json:"commitment"`
 }
 
 func (h *RoundsHandler) CreateRound(c *gin.Context) {
@@ -99,29 +103,43 @@ func (h *RoundsHandler) CreateRound(c *gin.Context) {
 		return
 	}
 
-	game, err := h.queries.GetGameByCode(ctx, code)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			NotFound(c, "game not found")
-		} else {
-			InternalServerError(c, "db error")
+	var (
+		game     database.Game
+		question database.GetNextQueuedQuestionRow
+		round    database.CreateRoundRow
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, err = lockGame(ctx, q, code)
+		if err != nil {
+			return err
 		}
-		return
-	}
 
-	question, err := h.queries.GetRandomQuestionByLevel(ctx, game.Level)
-	if err != nil {
-		InternalServerError(c, "failed to pick question")
-		return
-	}
+		if err := domain.GameStatus(game.Status).TransitionTo(domain.GamePlaying); err != nil {
+			return err
+		}
 
-	round, err := h.queries.CreateRound(ctx, database.CreateRoundParams{
-		GameID:          game.ID,
-		QuestionID:      question.ID,
-		CurrentPlayerID: req.PlayerID,
+		if _, err := activePlayer(ctx, q, game.ID, req.PlayerID); err != nil {
+			return err
+		}
+
+		question, err = h.drawQuestion(ctx, q, game)
+		if err != nil {
+			return err
+		}
+
+		round, err = h.createRound(ctx, q, game, req.PlayerID, question.ID)
+		if err != nil {
+			return err
+		}
+
+		return q.UpdateGameStatus(ctx, database.UpdateGameStatusParams{
+			ID:     game.ID,
+			Status: string(domain.GamePlaying),
+		})
 	})
 	if err != nil {
-		InternalServerError(c, "failed to create round")
+		respondError(c, h.logger, err, "failed to create round")
 		return
 	}
 
@@ -130,8 +148,9 @@ func (h *RoundsHandler) CreateRound(c *gin.Context) {
 	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
 		Type: "game_started",
 		Data: gin.H{
-			"roundId":  round.ID,
-			"playerId": round.CurrentPlayerID,
+			"roundId":    round.ID,
+			"playerId":   round.CurrentPlayerID,
+			"commitment": round.Commitment.String,
 		},
 	})
 
@@ -143,10 +162,13 @@ func (h *RoundsHandler) CreateRound(c *gin.Context) {
 		},
 	})
 
+	h.startTimer(game.Code, round)
+
 	// ✅ 回傳給建立 round 的前端（主持人）
 	Success(c, CreateRoundResponse{
-		RoundID:  round.ID,
-		PlayerID: round.CurrentPlayerID,
+		RoundID:    round.ID,
+		PlayerID:   round.CurrentPlayerID,
+		Commitment: round.Commitment.String,
 	})
 
 }
@@ -163,53 +185,118 @@ func (h *RoundsHandler) DrawCard(c *gin.Context) {
 		return
 	}
 
-	game, err := h.queries.GetGameByCode(ctx, gameCode)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			NotFound(c, "game not found")
-			return
-		}
-		InternalServerError(c, "db error")
+	if _, err := h.drawCard(ctx, gameCode, roundID); err != nil {
+		respondError(c, h.logger, err, "failed to draw card")
 		return
 	}
 
-	round, err := h.queries.GetRoundByID(ctx, roundID)
+	c.Status(http.StatusOK)
+}
+
+type DrawCardResult struct {
+	RoundID  int64 `json:"roundId"`
+	PlayerID int64 `json:"playerId"`
+	IsJoker  bool  `json:"isJoker"`
+}
+
+// drawCard draws the card of a round at the drawer's request and announces
+// the outcome. When the game votes on answers the answer must have been
+// through the vote.
+func (h *RoundsHandler) drawCard(ctx context.Context, gameCode string, roundID int64) (DrawCardResult, error) {
+	return h.draw(ctx, gameCode, roundID, false)
+}
+
+// draw draws the card of a round in progress. forced skips the vote, for a
+// drawer who ran out of time.
+func (h *RoundsHandler) draw(ctx context.Context, gameCode string, roundID int64, forced bool) (DrawCardResult, error) {
+	var (
+		game     database.Game
+		round    database.Round
+		question string
+		isJoker  bool
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, round, err = lockRound(ctx, q, gameCode, roundID)
+		if err != nil {
+			return err
+		}
+		question, isJoker, err = markDrawn(ctx, q, game, round, forced)
+		return err
+	})
 	if err != nil {
-		InternalServerError(c, "round not found")
-		return
+		return DrawCardResult{}, err
 	}
 
-	if round.Status != "pending" {
-		BadRequest(c, "round already completed")
-		return
-	}
+	return h.announceDrawn(ctx, game, round, question, isJoker), nil
+}
 
-	// 🎲 抽鬼牌（1/3 機率）
-	isJoker := rand.Intn(3) == 0
+// markDrawn draws the card of a locked round and records the outcome. It
+// returns the question, which a joker reveals to everyone.
+func markDrawn(ctx context.Context, q database.Store, game database.Game, round database.Round, forced bool) (string, bool, error) {
+	// 🎲 由回合建立時承諾的 seed 決定結果，事後可驗證
+	var input fairness.Input
+	if err := json.Unmarshal(round.DrawInput, &input); err != nil {
+		return "", false, err
+	}
+	isJoker, state := fairness.Outcome(round.Seed, input, round.Penalty)
 
-	newStatus := "done"
+	newStatus := domain.RoundDone
 	if isJoker {
-		newStatus = "revealed"
+		newStatus = domain.RoundRevealed
+	}
+
+	// 在 game 的鎖之下檢查狀態，同一回合只能抽一次
+	status := domain.RoundStatus(round.Status)
+	if !forced {
+		if err := domain.CanDraw(status, domain.VoteRule(game.VoteRule)); err != nil {
+			return "", false, err
+		}
+	}
+	if err := status.TransitionTo(newStatus); err != nil {
+		return "", false, err
+	}
+
+	err := q.UpdateGameDrawState(ctx, database.UpdateGameDrawStateParams{
+		ID:              game.ID,
+		DrawsSinceJoker: int32(state.SinceJoker),
+		PileSafeLeft:    int32(state.SafeLeft),
+		PileJokersLeft:  int32(state.JokersLeft),
+	})
+	if err != nil {
+		return "", false, err
 	}
 
-	err = h.queries.UpdateRoundStatus(ctx, database.UpdateRoundStatusParams{
+	err = q.UpdateRoundStatus(ctx, database.UpdateRoundStatusParams{
 		ID: round.ID,
 		IsJoker: pgtype.Bool{
 			Bool:  isJoker,
 			Valid: true,
 		},
-		Status: newStatus,
+		Status: string(newStatus),
 	})
 	if err != nil {
-		InternalServerError(c, "failed to update round")
-		return
+		return "", false, err
 	}
 
-	question, err := h.queries.GetQuestionByID(ctx, round.QuestionID)
-	if err != nil {
-		InternalServerError(c, "failed to get question")
-		return
+	outcome := domain.OutcomeSafe
+	switch {
+	case forced:
+		outcome = domain.OutcomeTimedOut
+	case isJoker:
+		outcome = domain.OutcomeJoker
 	}
+	if err := recordOutcome(ctx, q, game, round, outcome, isJoker); err != nil {
+		return "", false, err
+	}
+
+	question, err := q.GetQuestionByID(ctx, round.QuestionID)
+	return question, isJoker, err
+}
+
+// announceDrawn tells the room how a round's draw came out.
+func (h *RoundsHandler) announceDrawn(ctx context.Context, game database.Game, round database.Round, question string, isJoker bool) DrawCardResult {
+	h.timers.remove(round.ID)
 
 	if isJoker {
 		// 👻 廣播給所有人：顯示題目
@@ -219,6 +306,8 @@ func (h *RoundsHandler) DrawCard(c *gin.Context) {
 				"roundId":  round.ID,
 				"playerId": round.CurrentPlayerID,
 				"question": question,
+				"penalty":  round.Penalty,
+				"seed":     hex.EncodeToString(round.Seed),
 			},
 		})
 	} else {
@@ -228,119 +317,368 @@ func (h *RoundsHandler) DrawCard(c *gin.Context) {
 			Data: gin.H{
 				"roundId":  round.ID,
 				"playerId": round.CurrentPlayerID,
+				"penalty":  round.Penalty,
+				"seed":     hex.EncodeToString(round.Seed),
 			},
 		})
 	}
 
-	c.Status(http.StatusOK)
+	h.broadcastScoreboard(ctx, game)
+
+	return DrawCardResult{
+		RoundID:  round.ID,
+		PlayerID: round.CurrentPlayerID,
+		IsJoker:  isJoker,
+	}
+}
+
+// NextRoundRequest picks the next player when the game's turn order lets the
+// caller choose; the body may be left out otherwise.
+type NextRoundRequest struct {
+	PlayerID int64 `json:"playerId"`
 }
 
 func (h *RoundsHandler) CreateNextRound(c *gin.Context) {
 	ctx := c.Request.Context()
 	gameCode := c.Param("code")
+	player := playerFrom(c)
 
-	game, err := h.queries.GetGameByCode(ctx, gameCode)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			NotFound(c, "game not found")
-			return
-		}
-		InternalServerError(c, "db error")
+	var req NextRoundRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		BadRequest(c, "invalid playerId")
 		return
 	}
 
-	players, err := h.queries.ListPlayersByGameCode(ctx, game.Code)
-	if err != nil || len(players) == 0 {
-		InternalServerError(c, "failed to get players")
+	round, err := h.nextRound(ctx, gameCode, player.ID, req.PlayerID)
+	if err != nil {
+		respondError(c, h.logger, err, "failed to create round")
 		return
 	}
 
-	lastRound, err := h.queries.GetLatestRoundInGame(ctx, game.ID)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		InternalServerError(c, "failed to get last round")
-		return
-	}
+	c.JSON(http.StatusOK, gin.H{
+		"round_id":   round.ID,
+		"player_id":  round.CurrentPlayerID,
+		"commitment": round.Commitment.String,
+	})
+}
 
-	// 決定下一位玩家
-	nextPlayerID := players[0].ID
-	if lastRound.ID != 0 {
-		for i, p := range players {
-			if p.ID == lastRound.CurrentPlayerID {
-				nextPlayerID = players[(i+1)%len(players)].ID
-				break
-			}
+// nextRound hands the turn to the next player and sends them the question.
+// callerID is the player starting the round, or 0 when the server does;
+// pickID is the player they picked, or 0. See pickNext.
+func (h *RoundsHandler) nextRound(ctx context.Context, gameCode string, callerID, pickID int64) (database.CreateRoundRow, error) {
+	var (
+		game     database.Game
+		question database.GetNextQueuedQuestionRow
+		round    database.CreateRoundRow
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		// 鎖住 game，同一場遊戲不會同時建立兩個下一回合
+		game, err = lockGame(ctx, q, gameCode)
+		if err != nil {
+			return err
 		}
-	}
 
-	question, err := h.queries.GetRandomQuestionByLevel(ctx, game.Level)
-	if err != nil {
-		InternalServerError(c, "failed to get question")
-		return
-	}
+		players, err := q.ListPlayersByGameCode(ctx, game.Code)
+		if err != nil {
+			return err
+		}
+		if len(players) == 0 {
+			return errors.New("game has no players")
+		}
 
-	round, err := h.queries.CreateRound(ctx, database.CreateRoundParams{
-		GameID:          game.ID,
-		QuestionID:      question.ID,
-		CurrentPlayerID: nextPlayerID,
+		lastRound, err := q.GetLatestRoundInGame(ctx, game.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		var latestStatus *domain.RoundStatus
+		if lastRound.ID != 0 {
+			status := domain.RoundStatus(lastRound.Status)
+			latestStatus = &status
+		}
+		if err := domain.CanStartNextRound(domain.GameStatus(game.Status), latestStatus); err != nil {
+			return err
+		}
+
+		nextPlayerID, err := h.pickNext(ctx, q, game, lastRound, players, callerID, pickID)
+		if err != nil {
+			return err
+		}
+
+		question, err = h.drawQuestion(ctx, q, game)
+		if err != nil {
+			return err
+		}
+
+		round, err = h.createRound(ctx, q, game, nextPlayerID, question.ID)
+		return err
 	})
 	if err != nil {
-		InternalServerError(c, "failed to create round")
-		return
+		return round, err
 	}
 
 	// 廣播回合開始（不含題目）
 	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
 		Type: "round_started",
 		Data: gin.H{
-			"roundId":  round.ID,
-			"playerId": nextPlayerID,
+			"roundId":    round.ID,
+			"playerId":   round.CurrentPlayerID,
+			"commitment": round.Commitment.String,
 		},
 	})
 
 	// 私訊題目給當事人
-	h.hub.SendToPlayer(game.Code, nextPlayerID, ws.WebSocketMessage{
+	h.hub.SendToPlayer(game.Code, round.CurrentPlayerID, ws.WebSocketMessage{
 		Type: "round_question",
 		Data: gin.H{
 			"question": question.Content,
 		},
 	})
 
-	c.JSON(http.StatusOK, gin.H{
-		"round_id":  round.ID,
-		"player_id": nextPlayerID,
+	h.startTimer(game.Code, round)
+
+	return round, nil
+}
+
+// createRound starts a pending round and commits to the seed that will
+// decide its card. The draw input is taken from the game now, so the outcome
+// is fixed from the moment the commitment is published.
+func (h *RoundsHandler) createRound(ctx context.Context, q database.Store, game database.Game, playerID, questionID int64) (database.CreateRoundRow, error) {
+	seed, err := fairness.NewSeed(h.rng)
+	if err != nil {
+		return database.CreateRoundRow{}, err
+	}
+
+	input, err := json.Marshal(fairness.Input{
+		Rules: rulesFromGame(game),
+		State: drawStateFromGame(game),
+	})
+	if err != nil {
+		return database.CreateRoundRow{}, err
+	}
+
+	return q.CreateRound(ctx, database.CreateRoundParams{
+		GameID:          game.ID,
+		QuestionID:      questionID,
+		CurrentPlayerID: playerID,
+		Seed:            seed,
+		Commitment:      pgtype.Text{String: fairness.Commit(seed), Valid: true},
+		DrawInput:       input,
+		DeadlineAt:      h.deadlineFor(game),
 	})
 }
 
-func (h *RoundsHandler) EndGame(c *gin.Context) {
+// drawQuestion takes the next question from the game's shuffled queue, so
+// no question repeats until the whole pool has been used. An exhausted queue
+// is rebuilt from the pool and drawn from the start.
+func (h *RoundsHandler) drawQuestion(ctx context.Context, q database.Store, game database.Game) (database.GetNextQueuedQuestionRow, error) {
+	question, err := q.GetNextQueuedQuestion(ctx, database.GetNextQueuedQuestionParams{
+		GameID:   game.ID,
+		Position: game.QuestionCursor,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := h.refillQuestionQueue(ctx, q, game); err != nil {
+			return question, err
+		}
+		question, err = q.GetNextQueuedQuestion(ctx, database.GetNextQueuedQuestionParams{
+			GameID: game.ID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return question, errNoQuestions
+		}
+	}
+	if err != nil {
+		return question, err
+	}
+
+	err = q.UpdateGameQuestionCursor(ctx, database.UpdateGameQuestionCursorParams{
+		ID:             game.ID,
+		QuestionCursor: question.Position + 1,
+	})
+	return question, err
+}
+
+// refillQuestionQueue reshuffles the game's pool: its deck, or the level
+// pool when the game has no deck or the deck is empty.
+func (h *RoundsHandler) refillQuestionQueue(ctx context.Context, q database.Store, game database.Game) error {
+	var (
+		ids []int64
+		err error
+	)
+	if game.DeckID.Valid {
+		ids, err = q.ListDeckQuestionIDs(ctx, game.DeckID.Int64)
+		if err != nil {
+			return err
+		}
+	}
+	if len(ids) == 0 {
+		ids, err = q.ListQuestionIDsByLevel(ctx, game.Level)
+		if err != nil {
+			return err
+		}
+	}
+
+	h.rng.Shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+
+	// 新一輪的第一題不要和上一題相同
+	last, err := q.GetLatestRoundInGame(ctx, game.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if len(ids) > 1 && ids[0] == last.QuestionID {
+		ids[0], ids[len(ids)-1] = ids[len(ids)-1], ids[0]
+	}
+
+	if err := q.ClearQuestionQueue(ctx, game.ID); err != nil {
+		return err
+	}
+	return q.FillQuestionQueue(ctx, database.FillQuestionQueueParams{
+		GameID:      game.ID,
+		QuestionIds: ids,
+	})
+}
+
+type VerifyRoundResponse struct {
+	RoundID    int64          `json:"roundId"`
+	Status     string         `json:"status"`
+	Commitment string         `json:"commitment"`
+	Input      fairness.Input `json:"input"`
+	Penalty    bool           `json:"penalty"`
+	// Seed, IsJoker and Verified stay null until the card is drawn. A
+	// skipped round reveals its seed but has no card.
+	Seed     *string `json:"seed"`
+	IsJoker  *bool   `json:"isJoker"`
+	Verified *bool   `json:"verified"`
+}
+
+// VerifyRound publishes what is needed to check a round: the commitment and
+// draw input from the start, and once drawn the seed and whether it
+// reproduces the recorded outcome. Rounds drawn before commitments existed
+// have none and are reported as not verifiable.
+func (h *RoundsHandler) VerifyRound(c *gin.Context) {
 	ctx := c.Request.Context()
-	gameCode := c.Param("code")
 
-	game, err := h.queries.GetGameByCode(ctx, gameCode)
+	roundID, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid round id")
+		return
+	}
+
+	game, err := h.store.GetGameByCode(ctx, c.Param("code"))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			NotFound(c, "game not found")
-			return
+			err = errGameNotFound
 		}
-		InternalServerError(c, "db error")
+		respondError(c, h.logger, err, "failed to verify round")
 		return
 	}
 
-	err = h.queries.UpdateGameStatus(ctx, database.UpdateGameStatusParams{
-		ID:     game.ID,
-		Status: "ended",
-	})
+	round, err := h.store.GetRoundByID(ctx, roundID)
+	if err != nil || round.GameID != game.ID {
+		if err == nil || errors.Is(err, sql.ErrNoRows) {
+			err = errRoundNotFound
+		}
+		respondError(c, h.logger, err, "failed to verify round")
+		return
+	}
+
+	// 在加入承諾之前就抽完的回合沒有 seed，無從驗證
+	if !round.Commitment.Valid {
+		respondError(c, h.logger, errNotVerifiable, "failed to verify round")
+		return
+	}
+
+	var input fairness.Input
+	if err := json.Unmarshal(round.DrawInput, &input); err != nil {
+		respondError(c, h.logger, err, "failed to verify round")
+		return
+	}
+
+	resp := VerifyRoundResponse{
+		RoundID:    round.ID,
+		Status:     round.Status,
+		Commitment: round.Commitment.String,
+		Input:      input,
+		Penalty:    round.Penalty,
+	}
+	// 還沒抽牌前不能公開 seed；被跳過的回合只驗證 seed 本身
+	status := domain.RoundStatus(round.Status)
+	if status.IsFinal() {
+		seed := hex.EncodeToString(round.Seed)
+		verified := fairness.Matches(round.Seed, round.Commitment.String)
+		if status.IsDrawn() {
+			isJoker, _ := fairness.Outcome(round.Seed, input, round.Penalty)
+			verified = verified && isJoker == round.IsJoker.Bool
+			resp.IsJoker = &round.IsJoker.Bool
+		}
+		resp.Seed = &seed
+		resp.Verified = &verified
+	}
+
+	Success(c, resp)
+}
+
+func (h *RoundsHandler) EndGame(c *gin.Context) {
+	ctx := c.Request.Context()
+	gameCode := c.Param("code")
+
+	final, err := h.endGame(ctx, gameCode)
 	if err != nil {
-		InternalServerError(c, "failed to end game")
+		respondError(c, h.logger, err, "failed to end game")
 		return
 	}
 
-	// 廣播遊戲結束
+	Success(c, final)
+}
+
+// endGame ends the game and announces the final standings.
+func (h *RoundsHandler) endGame(ctx context.Context, gameCode string) (Standings, error) {
+	var (
+		game  database.Game
+		board Scoreboard
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, err = lockGame(ctx, q, gameCode)
+		if err != nil {
+			return err
+		}
+
+		if err := domain.GameStatus(game.Status).TransitionTo(domain.GameEnded); err != nil {
+			return err
+		}
+
+		err = q.UpdateGameStatus(ctx, database.UpdateGameStatusParams{
+			ID:     game.ID,
+			Status: string(domain.GameEnded),
+		})
+		if err != nil {
+			return err
+		}
+
+		board, err = loadScoreboard(ctx, q, game)
+		return err
+	})
+	if err != nil {
+		return Standings{}, err
+	}
+
+	h.timers.removeGame(game.Code)
+
+	final := standings(board)
+
+	// 廣播遊戲結束與最終排名
 	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
 		Type: "game_ended",
 		Data: gin.H{
-			"game_id": game.ID,
+			"game_id":     game.ID,
+			"standings":   final.Entries,
+			"winner":      final.Winner,
+			"mostExposed": final.MostExposed,
 		},
 	})
-
-	c.Status(http.StatusOK)
+	return final, nil
 }
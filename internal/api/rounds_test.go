@@ -0,0 +1,328 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/utils"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// testStores returns the stores the round tests run against: always the
+// memory store, plus Postgres when TEST_DB_URL points at a migrated database.
+// With TEST_DB_REQUIRED set, as in CI, a missing TEST_DB_URL fails the test
+// instead of leaving the row locks untested.
+func testStores(t *testing.T) map[string]database.Store {
+	t.Helper()
+	stores := map[string]database.Store{
+		"memory": memory.New(utils.NewFakeClock(time.Unix(1_700_000_000, 0))),
+	}
+
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		if os.Getenv("TEST_DB_REQUIRED") != "" {
+			t.Fatal("TEST_DB_REQUIRED is set but TEST_DB_URL is empty")
+		}
+		return stores
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect to TEST_DB_URL: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	stores["postgres"] = database.New(pool)
+	return stores
+}
+
+func newTestRoundsHandler(t *testing.T, store database.Store) *RoundsHandler {
+	t.Helper()
+	hub := ws.NewHub(ws.DefaultConfig(), ws.NewLocalBackplane())
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewRoundsHandler(store, logger, hub, utils.NewSeededRandom(1), utils.SystemClock{})
+}
+
+// startTestGame creates a playing game with the given players and questions.
+// The game has no rounds yet.
+func startTestGame(t *testing.T, store database.Store, players int, questions ...string) (database.Game, []database.CreatePlayerRow) {
+	t.Helper()
+	return startTestGameWith(t, store, func(*database.CreateGameParams) {}, players, questions...)
+}
+
+// startTestGameWith is startTestGame with edit applied to the game's default
+// settings.
+func startTestGameWith(t *testing.T, store database.Store, edit func(*database.CreateGameParams), players int, questions ...string) (database.Game, []database.CreatePlayerRow) {
+	t.Helper()
+	ctx := context.Background()
+
+	// 共用的 Postgres 測試庫裡可能已經有同一題，有就沿用
+	for _, content := range questions {
+		_, err := store.ImportQuestion(ctx, database.ImportQuestionParams{Level: "easy", Content: content})
+		if err != nil {
+			t.Fatalf("create question: %v", err)
+		}
+	}
+
+	code, err := utils.GenerateUniqueGameCode(ctx, store, utils.NewCryptoRandom(), 6, 5)
+	if err != nil {
+		t.Fatalf("generate code: %v", err)
+	}
+	rules, timer, scoring := domain.DefaultRules(), domain.DefaultTimer(), domain.DefaultScoring()
+	params := database.CreateGameParams{
+		Code:             code,
+		Level:            "easy",
+		Status:           string(domain.GamePlaying),
+		DrawMode:         string(rules.Mode),
+		JokerProbability: rules.JokerProbability,
+		PityDraws:        int32(rules.PityDraws),
+		PileSafeCards:    int32(rules.PileSafe),
+		PileJokers:       int32(rules.PileJokers),
+		TimeoutAction:    string(timer.Action),
+		VoteRule:         string(domain.VoteOff),
+		PointsSafe:       int32(scoring.Safe),
+		TurnOrder:        string(domain.TurnSequential),
+	}
+	edit(&params)
+	game, err := store.CreateGame(ctx, params)
+	if err != nil {
+		t.Fatalf("create game: %v", err)
+	}
+
+	var rows []database.CreatePlayerRow
+	for i := range players {
+		row, err := store.CreatePlayer(ctx, database.CreatePlayerParams{
+			GameID:   game.ID,
+			Nickname: fmt.Sprintf("p%d", i),
+			IsHost:   pgtype.Bool{Bool: i == 0, Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("create player: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	return game, rows
+}
+
+// race runs fn on n goroutines at once and returns the errors they returned.
+func race(n int, fn func() error) []error {
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+		errs  = make([]error, n)
+	)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs[i] = fn()
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+// checkOneWinner fails unless exactly one call succeeded and every other one
+// was refused with a conflict.
+func checkOneWinner(t *testing.T, errs []error) {
+	t.Helper()
+	wins := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			wins++
+		case errorStatus(err) != http.StatusConflict:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if wins != 1 {
+		t.Fatalf("%d calls succeeded, want 1", wins)
+	}
+}
+
+func TestConcurrentDrawCard(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			h := newTestRoundsHandler(t, store)
+			game, _ := startTestGame(t, store, 3, "draw once a", "draw once b")
+
+			round, err := h.nextRound(ctx, game.Code, 0, 0)
+			if err != nil {
+				t.Fatalf("start round: %v", err)
+			}
+
+			checkOneWinner(t, race(20, func() error {
+				_, err := h.drawCard(ctx, game.Code, round.ID)
+				return err
+			}))
+
+			scoreboard, err := store.ListScoreboard(ctx, game.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rounds int64
+			for _, entry := range scoreboard {
+				rounds += entry.Rounds
+			}
+			if rounds != 1 {
+				t.Fatalf("recorded %d outcomes, want 1", rounds)
+			}
+		})
+	}
+}
+
+func TestConcurrentCreateNextRound(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			h := newTestRoundsHandler(t, store)
+			game, _ := startTestGame(t, store, 3, "next once a", "next once b", "next once c")
+
+			round, err := h.nextRound(ctx, game.Code, 0, 0)
+			if err != nil {
+				t.Fatalf("start round: %v", err)
+			}
+			if _, err := h.drawCard(ctx, game.Code, round.ID); err != nil {
+				t.Fatalf("draw: %v", err)
+			}
+
+			checkOneWinner(t, race(20, func() error {
+				_, err := h.nextRound(ctx, game.Code, 0, 0)
+				return err
+			}))
+
+			count, err := store.CountRoundsInGame(ctx, game.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 2 {
+				t.Fatalf("game has %d rounds, want 2", count)
+			}
+		})
+	}
+}
+
+func TestQuestionsDoNotRepeat(t *testing.T) {
+	const (
+		pool   = 3
+		cycles = 4
+	)
+	// 每個 seed 洗出不同順序，總會遇到新一輪第一題剛好是上一題的情況
+	for seed := range int64(30) {
+		ctx := context.Background()
+		store := memory.New(utils.SystemClock{})
+		h := newTestRoundsHandler(t, store)
+		h.rng = utils.NewSeededRandom(seed)
+		game, _ := startTestGame(t, store, 2, "no repeat a", "no repeat b", "no repeat c")
+
+		var asked []int64
+		for range pool * cycles {
+			round, err := h.nextRound(ctx, game.Code, 0, 0)
+			if err != nil {
+				t.Fatalf("seed %d: next round: %v", seed, err)
+			}
+			if _, err := h.drawCard(ctx, game.Code, round.ID); err != nil {
+				t.Fatalf("seed %d: draw: %v", seed, err)
+			}
+			asked = append(asked, round.QuestionID)
+		}
+
+		for cycle := range cycles {
+			seen := map[int64]bool{}
+			for _, id := range asked[cycle*pool : (cycle+1)*pool] {
+				if seen[id] {
+					t.Fatalf("seed %d: question %d repeated within cycle %d: %v", seed, id, cycle, asked)
+				}
+				seen[id] = true
+			}
+		}
+		for i := 1; i < len(asked); i++ {
+			if asked[i] == asked[i-1] {
+				t.Fatalf("seed %d: question %d asked twice in a row across a reshuffle: %v", seed, asked[i], asked)
+			}
+		}
+	}
+}
+
+func TestVerifyRound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	verify := func(h *RoundsHandler, code string, roundID int64) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Params = gin.Params{{Key: "code", Value: code}, {Key: "id", Value: fmt.Sprint(roundID)}}
+		h.VerifyRound(c)
+		return w
+	}
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			h := newTestRoundsHandler(t, store)
+			game, players := startTestGame(t, store, 2, "verify a", "verify b")
+
+			questions, err := store.ListQuestionIDsByLevel(ctx, game.Level)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 加入承諾之前抽完的回合沒有 seed
+			legacy, err := store.CreateRound(ctx, database.CreateRoundParams{
+				GameID:          game.ID,
+				QuestionID:      questions[0],
+				CurrentPlayerID: players[0].ID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.UpdateRoundStatus(ctx, database.UpdateRoundStatusParams{
+				ID:      legacy.ID,
+				IsJoker: pgtype.Bool{Valid: true},
+				Status:  string(domain.RoundDone),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w := verify(h, game.Code, legacy.ID); w.Code != http.StatusConflict {
+				t.Fatalf("legacy round: status %d, body %s", w.Code, w.Body)
+			}
+
+			round, err := h.nextRound(ctx, game.Code, 0, 0)
+			if err != nil {
+				t.Fatalf("start round: %v", err)
+			}
+			if _, err := h.drawCard(ctx, game.Code, round.ID); err != nil {
+				t.Fatalf("draw: %v", err)
+			}
+			w := verify(h, game.Code, round.ID)
+			var resp struct {
+				Data VerifyRoundResponse `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != http.StatusOK || resp.Data.Verified == nil || !*resp.Data.Verified {
+				t.Fatalf("drawn round: status %d, body %s", w.Code, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// ScoreboardEntry is one player's score. Players on equal points share a
+// rank.
+type ScoreboardEntry struct {
+	Rank     int    `json:"rank"`
+	PlayerID int64  `json:"playerId"`
+	Nickname string `json:"nickname"`
+	Left     bool   `json:"left"`
+	Points   int    `json:"points"`
+	Rounds   int    `json:"rounds"`
+	Safe     int    `json:"safe"`
+	Jokers   int    `json:"jokers"`
+	Skipped  int    `json:"skipped"`
+	TimedOut int    `json:"timedOut"`
+}
+
+// Scoreboard lists the players by points, then by fewest jokers. Players
+// who left keep their score.
+type Scoreboard struct {
+	Scoring GameScoring       `json:"scoring"`
+	Entries []ScoreboardEntry `json:"entries"`
+}
+
+// Standings are the final scoreboard of an ended game. Winner and
+// MostExposed stay nil until somebody played a round or revealed a joker.
+type Standings struct {
+	Scoreboard
+	Winner      *ScoreboardEntry `json:"winner"`
+	MostExposed *ScoreboardEntry `json:"mostExposed"`
+}
+
+func (h *RoundsHandler) GetScoreboard(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	game, err := h.store.GetGameByCode(ctx, c.Param("code"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NotFound(c, "game not found")
+			return
+		}
+		h.logger.Error("get game failed", "error", err)
+		InternalServerError(c, "failed to get scoreboard")
+		return
+	}
+
+	board, err := loadScoreboard(ctx, h.store, game)
+	if err != nil {
+		respondError(c, h.logger, err, "failed to get scoreboard")
+		return
+	}
+
+	Success(c, board)
+}
+
+// recordOutcome stores how the round ended and the points its drawer earned.
+func recordOutcome(ctx context.Context, q database.Store, game database.Game, round database.Round, outcome domain.Outcome, exposed bool) error {
+	return q.CreateRoundOutcome(ctx, database.CreateRoundOutcomeParams{
+		RoundID:  round.ID,
+		GameID:   game.ID,
+		PlayerID: round.CurrentPlayerID,
+		Outcome:  string(outcome),
+		Exposed:  exposed,
+		Points:   int32(scoringFromGame(game).Points(outcome)),
+	})
+}
+
+func loadScoreboard(ctx context.Context, q database.Store, game database.Game) (Scoreboard, error) {
+	rows, err := q.ListScoreboard(ctx, game.ID)
+	if err != nil {
+		return Scoreboard{}, err
+	}
+
+	board := Scoreboard{
+		Scoring: toGameScoring(scoringFromGame(game)),
+		Entries: make([]ScoreboardEntry, 0, len(rows)),
+	}
+	for i, row := range rows {
+		entry := ScoreboardEntry{
+			Rank:     i + 1,
+			PlayerID: row.PlayerID,
+			Nickname: row.Nickname,
+			Left:     row.Left,
+			Points:   int(row.Points),
+			Rounds:   int(row.Rounds),
+			Safe:     int(row.Safe),
+			Jokers:   int(row.Jokers),
+			Skipped:  int(row.Skipped),
+			TimedOut: int(row.TimedOut),
+		}
+		if i > 0 && board.Entries[i-1].Points == entry.Points {
+			entry.Rank = board.Entries[i-1].Rank
+		}
+		board.Entries = append(board.Entries, entry)
+	}
+	return board, nil
+}
+
+// standings picks the winner, the top of the scoreboard among players still
+// in the game, and the player who revealed the most jokers, the lower scorer
+// on a tie.
+func standings(board Scoreboard) Standings {
+	s := Standings{Scoreboard: board}
+	for i := range board.Entries {
+		entry := &board.Entries[i]
+		if s.Winner == nil && entry.Rounds > 0 && !entry.Left {
+			s.Winner = entry
+		}
+		if entry.Jokers > 0 && (s.MostExposed == nil || entry.Jokers >= s.MostExposed.Jokers) {
+			s.MostExposed = entry
+		}
+	}
+	return s
+}
+
+// broadcastScoreboard sends the game's scoreboard after a round ended.
+func (h *RoundsHandler) broadcastScoreboard(ctx context.Context, game database.Game) {
+	board, err := loadScoreboard(ctx, h.store, game)
+	if err != nil {
+		h.logger.Error("load scoreboard failed", "game", game.Code, "error", err)
+		return
+	}
+
+	// 🏆 廣播最新分數
+	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
+		Type: "scoreboard_updated",
+		Data: board,
+	})
+}
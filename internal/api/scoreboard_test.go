@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+)
+
+// scoredOutcome is one finished round of a scoreboard test.
+type scoredOutcome struct {
+	player  int
+	outcome domain.Outcome
+	exposed bool
+}
+
+// scoreTestGame starts a game with the given scoring and records outcomes
+// the way finished rounds do.
+func scoreTestGame(t *testing.T, store database.Store, scoring domain.Scoring, players int, outcomes []scoredOutcome) (database.Game, []int64) {
+	t.Helper()
+	ctx := context.Background()
+	game, rows := startTestGameWith(t, store, func(p *database.CreateGameParams) {
+		p.PointsSafe = int32(scoring.Safe)
+		p.PointsJoker = int32(scoring.Joker)
+		p.PointsSkipped = int32(scoring.Skipped)
+		p.PointsTimedOut = int32(scoring.TimedOut)
+	}, players, "score")
+
+	questions, err := store.ListQuestionIDsByLevel(ctx, game.Level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int64
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	for _, o := range outcomes {
+		row, err := store.CreateRound(ctx, database.CreateRoundParams{
+			GameID:          game.ID,
+			QuestionID:      questions[0],
+			CurrentPlayerID: ids[o.player],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = store.UpdateRoundStatus(ctx, database.UpdateRoundStatusParams{
+			ID:      row.ID,
+			IsJoker: pgtype.Bool{Bool: o.exposed, Valid: true},
+			Status:  string(domain.RoundDone),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		round := database.Round{ID: row.ID, GameID: game.ID, CurrentPlayerID: ids[o.player]}
+		if err := recordOutcome(ctx, store, game, round, o.outcome, o.exposed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return game, ids
+}
+
+// callGame runs a game handler and decodes the data of its response.
+func callGame(t *testing.T, handler gin.HandlerFunc, code string, out any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "code", Value: code}}
+	handler(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	resp := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScoreboardPoints(t *testing.T) {
+	scoring := domain.Scoring{Safe: 3, Joker: -2, Skipped: -1, TimedOut: 0}
+	outcomes := []scoredOutcome{
+		{0, domain.OutcomeSafe, false},
+		{0, domain.OutcomeSafe, false},
+		{1, domain.OutcomeSafe, false},
+		{1, domain.OutcomeSafe, false},
+		{2, domain.OutcomeJoker, true},
+		{2, domain.OutcomeJoker, true},
+		{2, domain.OutcomeSafe, false},
+		{3, domain.OutcomeJoker, true},
+		{3, domain.OutcomeTimedOut, true},
+		{3, domain.OutcomeSkipped, false},
+	}
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newTestRoundsHandler(t, store)
+			game, ids := scoreTestGame(t, store, scoring, 5, outcomes)
+
+			var board Scoreboard
+			callGame(t, h.GetScoreboard, game.Code, &board)
+			if board.Scoring != toGameScoring(scoring) {
+				t.Fatalf("scoring %+v, want %+v", board.Scoring, scoring)
+			}
+
+			// 同分同名次；同分時鬼牌少的、再來是先加入的排前面
+			want := []ScoreboardEntry{
+				{Rank: 1, PlayerID: ids[0], Points: 6, Rounds: 2, Safe: 2},
+				{Rank: 1, PlayerID: ids[1], Points: 6, Rounds: 2, Safe: 2},
+				{Rank: 3, PlayerID: ids[4]},
+				{Rank: 4, PlayerID: ids[2], Points: -1, Rounds: 3, Safe: 1, Jokers: 2},
+				{Rank: 5, PlayerID: ids[3], Points: -3, Rounds: 3, Jokers: 2, Skipped: 1, TimedOut: 1},
+			}
+			if len(board.Entries) != len(want) {
+				t.Fatalf("got %d entries, want %d", len(board.Entries), len(want))
+			}
+			for i, w := range want {
+				got := board.Entries[i]
+				got.Nickname = ""
+				if got != w {
+					t.Errorf("entry %d = %+v, want %+v", i, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestEndGameStandings(t *testing.T) {
+	tests := []struct {
+		name        string
+		outcomes    []scoredOutcome
+		leave       []int
+		winner      int
+		mostExposed int
+	}{
+		{
+			name:        "nobody played",
+			winner:      -1,
+			mostExposed: -1,
+		},
+		{
+			name: "top scorer wins",
+			outcomes: []scoredOutcome{
+				{0, domain.OutcomeSafe, false},
+				{1, domain.OutcomeSafe, false},
+				{1, domain.OutcomeSafe, false},
+				{2, domain.OutcomeJoker, true},
+			},
+			winner:      1,
+			mostExposed: 2,
+		},
+		{
+			name: "tied winners go to the fewer jokers",
+			outcomes: []scoredOutcome{
+				{0, domain.OutcomeSafe, false},
+				{0, domain.OutcomeJoker, true},
+				{0, domain.OutcomeSafe, false},
+				{1, domain.OutcomeSafe, false},
+			},
+			winner:      1,
+			mostExposed: 0,
+		},
+		{
+			name: "players who left cannot win",
+			outcomes: []scoredOutcome{
+				{0, domain.OutcomeSafe, false},
+				{0, domain.OutcomeSafe, false},
+				{1, domain.OutcomeSafe, false},
+			},
+			leave:       []int{0},
+			winner:      1,
+			mostExposed: -1,
+		},
+		{
+			name: "players who sat out cannot win",
+			outcomes: []scoredOutcome{
+				{0, domain.OutcomeJoker, true},
+			},
+			winner:      0,
+			mostExposed: 0,
+		},
+		{
+			name: "most exposed tie goes to the lower scorer",
+			outcomes: []scoredOutcome{
+				{0, domain.OutcomeJoker, true},
+				{0, domain.OutcomeSafe, false},
+				{1, domain.OutcomeJoker, true},
+				{2, domain.OutcomeSafe, false},
+			},
+			winner:      2,
+			mostExposed: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := testStores(t)["memory"]
+			h := newTestRoundsHandler(t, store)
+			game, ids := scoreTestGame(t, store, domain.Scoring{Safe: 1, Joker: -1}, 3, tt.outcomes)
+			for _, p := range tt.leave {
+				if err := h.removePlayer(ctx, game.Code, ids[p]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			watcher := watchGame(h, game.Code)
+
+			var final Standings
+			callGame(t, h.EndGame, game.Code, &final)
+
+			check := func(role string, got *ScoreboardEntry, want int) {
+				t.Helper()
+				switch {
+				case want < 0 && got != nil:
+					t.Errorf("%s = player %d, want none", role, got.PlayerID)
+				case want >= 0 && (got == nil || got.PlayerID != ids[want]):
+					t.Errorf("%s = %+v, want player %d", role, got, ids[want])
+				}
+			}
+			check("winner", final.Winner, tt.winner)
+			check("most exposed", final.MostExposed, tt.mostExposed)
+			for _, p := range tt.leave {
+				for _, entry := range final.Entries {
+					if entry.PlayerID == ids[p] && !entry.Left {
+						t.Errorf("player %d left but is not marked", ids[p])
+					}
+				}
+			}
+
+			var ended struct {
+				Standings   []ScoreboardEntry `json:"standings"`
+				Winner      *ScoreboardEntry  `json:"winner"`
+				MostExposed *ScoreboardEntry  `json:"mostExposed"`
+			}
+			expectMessage(t, watcher, "game_ended", &ended)
+			check("broadcast winner", ended.Winner, tt.winner)
+			check("broadcast most exposed", ended.MostExposed, tt.mostExposed)
+			if len(ended.Standings) != len(final.Entries) {
+				t.Errorf("broadcast %d standings, want %d", len(ended.Standings), len(final.Entries))
+			}
+		})
+	}
+}
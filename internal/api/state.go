@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/utils"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+type StateHandler struct {
+	logger *slog.Logger
+	store  database.Store
+	hub    *ws.Hub
+	clock  utils.Clock
+}
+
+func NewStateHandler(store database.Store, logger *slog.Logger, hub *ws.Hub, clock utils.Clock) *StateHandler {
+	return &StateHandler{
+		logger: logger,
+		store:  store,
+		hub:    hub,
+		clock:  clock,
+	}
+}
+
+// GameSnapshot is the whole game as one player sees it. Seq is the last
+// room event the snapshot is known to include: a client applies it, then
+// only the events numbered after it.
+type GameSnapshot struct {
+	Seq     int64            `json:"seq"`
+	Game    SnapshotGame     `json:"game"`
+	Players []SnapshotPlayer `json:"players"`
+	Round   *SnapshotRound   `json:"round"`
+	Drawer  *SnapshotPlayer  `json:"drawer"`
+	You     SnapshotViewer   `json:"you"`
+}
+
+type SnapshotGame struct {
+	Code      string      `json:"code"`
+	Level     string      `json:"level"`
+	Status    string      `json:"status"`
+	Rules     GameRules   `json:"rules"`
+	Timer     GameTimer   `json:"timer"`
+	Scoring   GameScoring `json:"scoring"`
+	VoteRule  string      `json:"voteRule"`
+	TurnOrder string      `json:"turnOrder"`
+}
+
+type SnapshotPlayer struct {
+	ID        int64  `json:"id"`
+	Nickname  string `json:"nickname"`
+	IsHost    bool   `json:"isHost"`
+	Seat      int32  `json:"seat"`
+	Connected bool   `json:"connected"`
+}
+
+// SnapshotRound hides the question unless the viewer may see it, and the
+// card and its seed until it has been drawn. Commitment and Seed are null
+// for rounds drawn before commitments existed. DeadlineAt is set while a timed
+// round is pending.
+type SnapshotRound struct {
+	ID              int64      `json:"id"`
+	Status          string     `json:"status"`
+	CurrentPlayerID int64      `json:"currentPlayerId"`
+	IsJoker         *bool      `json:"isJoker"`
+	Question        *string    `json:"question"`
+	Commitment      *string    `json:"commitment"`
+	Seed            *string    `json:"seed"`
+	DeadlineAt      *time.Time `json:"deadlineAt"`
+	Attempt         int32      `json:"attempt"`
+	Penalty         bool       `json:"penalty"`
+	// Votes is the live tally while the answer is being voted on.
+	Votes *domain.Tally `json:"votes"`
+}
+
+// SnapshotViewer tells the caller what they are allowed to do right now.
+type SnapshotViewer struct {
+	PlayerID        int64 `json:"playerId"`
+	IsHost          bool  `json:"isHost"`
+	IsDrawer        bool  `json:"isDrawer"`
+	CanSeeQuestion  bool  `json:"canSeeQuestion"`
+	CanDraw         bool  `json:"canDraw"`
+	CanStartRound   bool  `json:"canStartRound"`
+	CanEndGame      bool  `json:"canEndGame"`
+	CanRemovePlayer bool  `json:"canRemovePlayer"`
+	CanAnswer       bool  `json:"canAnswer"`
+	CanVote         bool  `json:"canVote"`
+	// MustPick is set when starting the next round takes the viewer's pick
+	// of who draws.
+	MustPick bool `json:"mustPick"`
+	// Vote is the viewer's ballot on the current answer, if any.
+	Vote *bool `json:"vote"`
+}
+
+func (h *StateHandler) GetState(c *gin.Context) {
+	ctx := c.Request.Context()
+	player := playerFrom(c)
+
+	snapshot, err := h.Snapshot(ctx, c.Param("code"), player.ID)
+	if err != nil {
+		respondError(c, h.logger, err, "failed to load game state")
+		return
+	}
+
+	Success(c, snapshot)
+}
+
+// Snapshot reads the whole game under a share lock on the game row, so it
+// never observes half of a round creation or draw.
+func (h *StateHandler) Snapshot(ctx context.Context, gameCode string, playerID int64) (GameSnapshot, error) {
+	// seq 要在讀資料庫之前取，快照才一定包含到它為止的事件
+	snapshot := GameSnapshot{Seq: h.hub.LastSeq(gameCode)}
+	// 連在別台 replica 的玩家只看得到 last_seen_at
+	connected := h.hub.ConnectedPlayers(gameCode)
+	seenCutoff := h.clock.Now().Add(-presenceTTL)
+
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		game, err := q.LockGameByCodeForShare(ctx, gameCode)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return errGameNotFound
+			}
+			return err
+		}
+		snapshot.Game = SnapshotGame{
+			Code:      game.Code,
+			Level:     game.Level,
+			Status:    game.Status,
+			Rules:     toGameRules(rulesFromGame(game)),
+			Timer:     toGameTimer(timerFromGame(game)),
+			Scoring:   toGameScoring(scoringFromGame(game)),
+			VoteRule:  game.VoteRule,
+			TurnOrder: game.TurnOrder,
+		}
+
+		players, err := q.ListPlayersByGameCode(ctx, game.Code)
+		if err != nil {
+			return err
+		}
+		snapshot.Players = make([]SnapshotPlayer, 0, len(players))
+		var viewer *SnapshotPlayer
+		for _, p := range players {
+			snapshot.Players = append(snapshot.Players, SnapshotPlayer{
+				ID:        p.ID,
+				Nickname:  p.Nickname,
+				IsHost:    p.IsHost.Bool,
+				Seat:      p.Seat,
+				Connected: connected[p.ID] || seenSince(p.LastSeenAt, seenCutoff),
+			})
+			if p.ID == playerID {
+				viewer = &snapshot.Players[len(snapshot.Players)-1]
+			}
+		}
+		if viewer == nil {
+			return errPlayerNotInGame
+		}
+
+		snapshot.You = SnapshotViewer{
+			PlayerID:        viewer.ID,
+			IsHost:          viewer.IsHost,
+			CanRemovePlayer: viewer.IsHost,
+			CanEndGame:      viewer.IsHost && domain.GameStatus(game.Status).CanTransitionTo(domain.GameEnded),
+		}
+
+		round, err := q.GetLatestRoundInGame(ctx, game.ID)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			snapshot.You.CanStartRound = viewer.IsHost &&
+				domain.GameStatus(game.Status).CanTransitionTo(domain.GamePlaying)
+			return nil
+		}
+
+		status := domain.RoundStatus(round.Status)
+		picker := turnPicker(game, round, players)
+		snapshot.You.CanStartRound = (viewer.IsHost || viewer.ID == picker) &&
+			domain.CanStartNextRound(domain.GameStatus(game.Status), &status) == nil
+		snapshot.You.MustPick = snapshot.You.CanStartRound && picker != 0
+		playing := domain.CanPlay(domain.GameStatus(game.Status)) == nil
+		voteRule := domain.VoteRule(game.VoteRule)
+		snapshot.You.IsDrawer = round.CurrentPlayerID == viewer.ID
+		snapshot.You.CanDraw = snapshot.You.IsDrawer && playing &&
+			domain.CanDraw(status, voteRule) == nil
+		snapshot.You.CanAnswer = snapshot.You.IsDrawer && playing &&
+			voteRule.Enabled() && status == domain.RoundPending
+		snapshot.You.CanVote = !snapshot.You.IsDrawer && playing && status == domain.RoundVoting
+		snapshot.You.CanSeeQuestion = snapshot.You.IsDrawer || status == domain.RoundRevealed
+
+		snapshot.Round = &SnapshotRound{
+			ID:              round.ID,
+			Status:          round.Status,
+			CurrentPlayerID: round.CurrentPlayerID,
+			Attempt:         round.Attempt,
+			Penalty:         round.Penalty,
+		}
+		if status == domain.RoundVoting {
+			tally, err := countVotes(ctx, q, round)
+			if err != nil {
+				return err
+			}
+			snapshot.Round.Votes = &tally
+
+			vote, err := q.GetRoundVote(ctx, database.GetRoundVoteParams{
+				RoundID: round.ID,
+				Attempt: round.Attempt,
+				VoterID: viewer.ID,
+			})
+			switch {
+			case err == nil:
+				snapshot.You.Vote = &vote.Accept
+			case !errors.Is(err, sql.ErrNoRows):
+				return err
+			}
+		}
+		if round.Commitment.Valid {
+			snapshot.Round.Commitment = &round.Commitment.String
+		}
+		if status.IsDrawn() {
+			snapshot.Round.IsJoker = &round.IsJoker.Bool
+		}
+		if status.IsFinal() {
+			if round.Seed != nil {
+				seed := hex.EncodeToString(round.Seed)
+				snapshot.Round.Seed = &seed
+			}
+		} else if round.DeadlineAt.Valid {
+			snapshot.Round.DeadlineAt = &round.DeadlineAt.Time
+		}
+		if snapshot.You.CanSeeQuestion {
+			question, err := q.GetQuestionByID(ctx, round.QuestionID)
+			if err != nil {
+				return err
+			}
+			snapshot.Round.Question = &question
+		}
+
+		for i := range snapshot.Players {
+			if snapshot.Players[i].ID == round.CurrentPlayerID {
+				drawer := snapshot.Players[i]
+				snapshot.Drawer = &drawer
+			}
+		}
+		return nil
+	})
+
+	return snapshot, err
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/utils"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+func TestSnapshotPresence(t *testing.T) {
+	ctx := context.Background()
+	clock := utils.NewFakeClock(time.Unix(1_700_000_000, 0))
+	store := memory.New(clock)
+	game, players := startTestGame(t, store, 4, "presence")
+	local, elsewhere, stale, never := players[0].ID, players[1].ID, players[2].ID, players[3].ID
+
+	hub := ws.NewHub(ws.DefaultConfig(), ws.NewLocalBackplane())
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+	hub.Register(&ws.Client{Send: make(chan []byte, 8), GameCode: game.Code, PlayerID: local, Hub: hub})
+
+	// 另一台 replica 剛蓋過章的玩家算在線，太久以前蓋的不算
+	touch := func(id int64, at time.Time) {
+		err := store.TouchPlayers(ctx, database.TouchPlayersParams{
+			SeenAt: pgtype.Timestamptz{Time: at, Valid: true},
+			Ids:    []int64{id},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch(elsewhere, clock.Now().Add(-presenceInterval))
+	touch(stale, clock.Now().Add(-presenceTTL-time.Second))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	snapshot, err := NewStateHandler(store, logger, hub, clock).Snapshot(ctx, game.Code, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int64]bool{local: true, elsewhere: true, stale: false, never: false}
+	for _, p := range snapshot.Players {
+		if p.Connected != want[p.ID] {
+			t.Errorf("player %s: connected %v, want %v", p.Nickname, p.Connected, want[p.ID])
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// timerResolution is how often RunTimers looks for expired deadlines.
+const timerResolution = time.Second
+
+// roundTimers holds the deadline of every timed round in progress, by round
+// ID. The deadline itself lives in rounds.deadline_at and every replica
+// reloads the set from there, so each one ticks for its own sockets; the one
+// that clears the deadline under the game's lock expires the round.
+type roundTimers struct {
+	mu      sync.Mutex
+	entries map[int64]*roundTimer
+}
+
+type roundTimer struct {
+	gameCode string
+	playerID int64
+	deadline time.Time
+	lastTick time.Time
+}
+
+func newRoundTimers() *roundTimers {
+	return &roundTimers{entries: make(map[int64]*roundTimer)}
+}
+
+func (t *roundTimers) add(roundID int64, timer *roundTimer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries[roundID] = timer
+}
+
+// replace swaps the set for the rounds loaded from the database, keeping the
+// last tick of timers already running.
+func (t *roundTimers) replace(rounds []database.ListTimedPendingRoundsRow, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries := make(map[int64]*roundTimer, len(rounds))
+	for _, r := range rounds {
+		timer := &roundTimer{
+			gameCode: r.GameCode,
+			playerID: r.CurrentPlayerID,
+			deadline: r.DeadlineAt.Time,
+			lastTick: now,
+		}
+		if old, ok := t.entries[r.ID]; ok {
+			timer.lastTick = old.lastTick
+		}
+		entries[r.ID] = timer
+	}
+	t.entries = entries
+}
+
+func (t *roundTimers) remove(roundID int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, roundID)
+}
+
+func (t *roundTimers) removeGame(gameCode string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, timer := range t.entries {
+		if timer.gameCode == gameCode {
+			delete(t.entries, id)
+		}
+	}
+}
+
+type timerEvent struct {
+	roundID int64
+	timer   roundTimer
+}
+
+// due removes the timers that expired by now and returns them, along with
+// the running timers whose last tick is at least tick old.
+func (t *roundTimers) due(now time.Time, tick time.Duration) (expired, ticks []timerEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, timer := range t.entries {
+		switch {
+		case !now.Before(timer.deadline):
+			delete(t.entries, id)
+			expired = append(expired, timerEvent{roundID: id, timer: *timer})
+		case tick > 0 && now.Sub(timer.lastTick) >= tick:
+			timer.lastTick = now
+			ticks = append(ticks, timerEvent{roundID: id, timer: *timer})
+		}
+	}
+	return expired, ticks
+}
+
+// RestoreTimers picks up the deadlines of rounds in progress stored in the
+// database. Call it once on startup, before RunTimers.
+func (h *RoundsHandler) RestoreTimers(ctx context.Context) error {
+	count, err := h.syncTimers(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		h.logger.Info("restored round timers", "count", count)
+	}
+	return nil
+}
+
+// syncTimers reloads the timed rounds in progress, including those started
+// on other replicas, and returns how many there are.
+func (h *RoundsHandler) syncTimers(ctx context.Context) (int, error) {
+	rounds, err := h.store.ListTimedPendingRounds(ctx)
+	if err != nil {
+		return 0, err
+	}
+	h.timers.replace(rounds, h.clock.Now())
+	return len(rounds), nil
+}
+
+// RunTimers reloads the timers, expires overdue rounds and sends a
+// timer_tick for every running timer each tick, until ctx is done. A zero
+// tick disables ticks. Ticks only go to this replica's sockets and are not
+// sequenced or kept for replay; clients can count down from deadlineAt
+// between them. It also stamps the players connected here as online and
+// replaces hosts no replica has seen for their grace period.
+func (h *RoundsHandler) RunTimers(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(timerResolution)
+	defer ticker.Stop()
+
+	var lastSeen time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := h.syncTimers(ctx); err != nil {
+			h.logger.Error("reload round timers failed", "error", err)
+		}
+
+		now := h.clock.Now()
+		expired, ticks := h.timers.due(now, tick)
+		for _, ev := range ticks {
+			h.hub.BroadcastLocal(ev.timer.gameCode, ws.WebSocketMessage{
+				Type: "timer_tick",
+				Data: gin.H{
+					"roundId":          ev.roundID,
+					"playerId":         ev.timer.playerID,
+					"remainingSeconds": remainingSeconds(ev.timer.deadline, now),
+				},
+			})
+		}
+		for _, ev := range expired {
+			h.expireRound(ctx, ev.roundID, ev.timer)
+		}
+		// 先幫連在這台的玩家蓋章，再找太久沒被看到的主持人
+		if now.Sub(lastSeen) >= presenceInterval {
+			h.markOnline(ctx, now)
+			h.replaceHosts(ctx, now)
+			lastSeen = now
+		}
+	}
+}
+
+// startTimer watches the deadline of a newly created round, if it has one.
+func (h *RoundsHandler) startTimer(gameCode string, round database.CreateRoundRow) {
+	if !round.DeadlineAt.Valid {
+		return
+	}
+
+	now := h.clock.Now()
+	h.timers.add(round.ID, &roundTimer{
+		gameCode: gameCode,
+		playerID: round.CurrentPlayerID,
+		deadline: round.DeadlineAt.Time,
+		lastTick: now,
+	})
+
+	// ⏱ 廣播倒數開始
+	h.hub.BroadcastToGame(gameCode, ws.WebSocketMessage{
+		Type: "timer_started",
+		Data: gin.H{
+			"roundId":          round.ID,
+			"playerId":         round.CurrentPlayerID,
+			"deadlineAt":       round.DeadlineAt.Time,
+			"remainingSeconds": remainingSeconds(round.DeadlineAt.Time, now),
+		},
+	})
+}
+
+// expireRound applies the game's timeout action to a round whose deadline
+// passed. Every replica watches the deadline; the one that locks the game
+// first clears the deadline and applies the action in the same transaction,
+// so a crash or failure leaves the deadline in place to be retried. A round
+// another replica expired, or that was drawn in the meantime, or whose game
+// ended, is left alone.
+func (h *RoundsHandler) expireRound(ctx context.Context, roundID int64, timer roundTimer) {
+	var (
+		game     database.Game
+		round    database.Round
+		action   domain.TimeoutAction
+		claimed  bool
+		question string
+		isJoker  bool
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		claimed = false
+		game, round, err = lockRound(ctx, q, timer.gameCode, roundID)
+		if err != nil {
+			return err
+		}
+		if domain.RoundStatus(round.Status).IsFinal() || !round.DeadlineAt.Valid {
+			return nil
+		}
+		if err := q.ClearRoundDeadline(ctx, round.ID); err != nil {
+			return err
+		}
+		claimed = true
+
+		action = timerFromGame(game).Action
+		if action == domain.TimeoutSkip {
+			return markSkipped(ctx, q, game, round)
+		}
+		question, isJoker, err = markDrawn(ctx, q, game, round, true)
+		return err
+	})
+	if err != nil {
+		if errorStatus(err) == http.StatusInternalServerError {
+			h.logger.Error("expire round failed", "round", roundID, "error", err)
+		}
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	// ⌛ 廣播時間到
+	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
+		Type: "timer_expired",
+		Data: gin.H{
+			"roundId":  round.ID,
+			"playerId": round.CurrentPlayerID,
+			"action":   action,
+		},
+	})
+
+	if action != domain.TimeoutSkip {
+		h.announceDrawn(ctx, game, round, question, isJoker)
+		return
+	}
+	h.announceSkipped(ctx, game, round)
+
+	// 跳過的回合已經提交，才開始下一回合；需要有人挑下一位時（如
+	// host_assigned）會回 ErrPickRequired，留給玩家處理
+	if _, err := h.nextRound(ctx, game.Code, 0, 0); err != nil && errorStatus(err) == http.StatusInternalServerError {
+		h.logger.Error("start round after timeout failed", "round", round.ID, "error", err)
+	}
+}
+
+// markSkipped ends a round in progress without drawing and records the
+// outcome.
+func markSkipped(ctx context.Context, q database.Store, game database.Game, round database.Round) error {
+	if err := domain.RoundStatus(round.Status).TransitionTo(domain.RoundSkipped); err != nil {
+		return err
+	}
+
+	err := q.UpdateRoundStatus(ctx, database.UpdateRoundStatusParams{
+		ID:      round.ID,
+		IsJoker: pgtype.Bool{},
+		Status:  string(domain.RoundSkipped),
+	})
+	if err != nil {
+		return err
+	}
+
+	return recordOutcome(ctx, q, game, round, domain.OutcomeSkipped, false)
+}
+
+func (h *RoundsHandler) announceSkipped(ctx context.Context, game database.Game, round database.Round) {
+	h.timers.remove(round.ID)
+
+	// ⏭ 廣播回合被跳過
+	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
+		Type: "round_skipped",
+		Data: gin.H{
+			"roundId":  round.ID,
+			"playerId": round.CurrentPlayerID,
+		},
+	})
+	h.broadcastScoreboard(ctx, game)
+}
+
+// deadlineFor returns the deadline of a round created now, or an invalid
+// timestamp when the game has no timer.
+func (h *RoundsHandler) deadlineFor(game database.Game) pgtype.Timestamptz {
+	timer := timerFromGame(game)
+	if !timer.Enabled() {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: h.clock.Now().Add(timer.Limit), Valid: true}
+}
+
+func remainingSeconds(deadline, now time.Time) int {
+	return max(0, int(math.Ceil(deadline.Sub(now).Seconds())))
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/utils"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// countExpired reads the client's messages until none arrives for a while
+// and returns how many were timer_expired.
+func countExpired(t *testing.T, client *ws.Client) int {
+	t.Helper()
+	expired := 0
+	for {
+		select {
+		case payload := <-client.Send:
+			var msg ws.WebSocketMessage
+			if err := json.Unmarshal(payload, &msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Type == "timer_expired" {
+				expired++
+			}
+		case <-time.After(200 * time.Millisecond):
+			return expired
+		}
+	}
+}
+
+func TestExpireRoundOnce(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			game, _ := startTestGameWith(t, store, func(p *database.CreateGameParams) {
+				p.AnswerTimeLimitSeconds = 30
+				p.TimeoutAction = string(domain.TimeoutSkip)
+			}, 2, "expire once a", "expire once b", "expire once c")
+
+			// 兩個 replica 共用資料庫與 backplane，都在看同一個倒數
+			bp := ws.NewLocalBackplane()
+			var replicas []*RoundsHandler
+			for range 2 {
+				hub := ws.NewHub(ws.DefaultConfig(), bp)
+				go hub.Run()
+				t.Cleanup(hub.Stop)
+				replicas = append(replicas, NewRoundsHandler(store, logger, hub, utils.NewSeededRandom(1), utils.SystemClock{}))
+			}
+			watcher := &ws.Client{Send: make(chan []byte, 64), GameCode: game.Code, PlayerID: 1, Hub: replicas[0].hub}
+			replicas[0].hub.Register(watcher)
+
+			round, err := replicas[0].nextRound(ctx, game.Code, 0, 0)
+			if err != nil {
+				t.Fatalf("start round: %v", err)
+			}
+			if err := replicas[1].RestoreTimers(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			// 沒有 deadline 的回合不處理
+			if err := store.ClearRoundDeadline(ctx, round.ID); err != nil {
+				t.Fatal(err)
+			}
+			countExpired(t, watcher)
+			replicas[1].expireRound(ctx, round.ID, roundTimer{gameCode: game.Code})
+			if got := countExpired(t, watcher); got != 0 {
+				t.Fatalf("round without deadline: got %d timer_expired, want 0", got)
+			}
+			got, err := store.GetRoundByID(ctx, round.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != string(domain.RoundPending) {
+				t.Fatalf("round without deadline: status %q, want %q", got.Status, domain.RoundPending)
+			}
+			err = store.ExecTx(ctx, func(q database.Store) error {
+				game, round, err := lockRound(ctx, q, game.Code, round.ID)
+				if err != nil {
+					return err
+				}
+				return markSkipped(ctx, q, game, round)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 兩邊同時到期，只有一邊廣播
+			round, err = replicas[0].nextRound(ctx, game.Code, 0, 0)
+			if err != nil {
+				t.Fatalf("start round: %v", err)
+			}
+			countExpired(t, watcher)
+			var wg sync.WaitGroup
+			for _, h := range replicas {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					h.expireRound(ctx, round.ID, roundTimer{gameCode: game.Code})
+				}()
+			}
+			wg.Wait()
+			if got := countExpired(t, watcher); got != 1 {
+				t.Fatalf("got %d timer_expired, want 1", got)
+			}
+		})
+	}
+}
+
+// failingOutcomes fails recording a round's outcome, after the round was
+// already updated in the same transaction.
+type failingOutcomes struct {
+	database.Store
+}
+
+func (s failingOutcomes) ExecTx(ctx context.Context, fn func(database.Store) error) error {
+	return s.Store.ExecTx(ctx, func(q database.Store) error {
+		return fn(failingOutcomes{q})
+	})
+}
+
+func (failingOutcomes) CreateRoundOutcome(context.Context, database.CreateRoundOutcomeParams) error {
+	return errors.New("outcome unavailable")
+}
+
+func TestExpireRoundRollsBack(t *testing.T) {
+	for _, action := range []domain.TimeoutAction{domain.TimeoutSkip, domain.TimeoutAutoDraw} {
+		t.Run(string(action), func(t *testing.T) {
+			ctx := context.Background()
+			store := memory.New(utils.SystemClock{})
+			game, _ := startTestGameWith(t, store, func(p *database.CreateGameParams) {
+				p.AnswerTimeLimitSeconds = 30
+				p.TimeoutAction = string(action)
+			}, 2, "rollback a", "rollback b")
+
+			h := newTestRoundsHandler(t, failingOutcomes{store})
+			round, err := h.nextRound(ctx, game.Code, 0, 0)
+			if err != nil {
+				t.Fatalf("start round: %v", err)
+			}
+
+			// 處理失敗時 deadline 也要留著，下一輪計時器才會重試
+			h.expireRound(ctx, round.ID, roundTimer{gameCode: game.Code})
+			got, err := store.GetRoundByID(ctx, round.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != string(domain.RoundPending) {
+				t.Errorf("status %q, want %q", got.Status, domain.RoundPending)
+			}
+			if !got.DeadlineAt.Valid {
+				t.Error("deadline cleared by a failed timeout action")
+			}
+		})
+	}
+}
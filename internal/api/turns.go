@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+type SetSeatsRequest struct {
+	PlayerIDs []int64 `json:"playerIds" binding:"required"`
+}
+
+type SeatResponse struct {
+	PlayerID int64 `json:"playerId"`
+	Seat     int32 `json:"seat"`
+}
+
+// SetSeats reorders the players in the lobby. The body lists every player in
+// the new turn order.
+func (h *PlayersHandler) SetSeats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req SetSeatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "playerIds is required")
+		return
+	}
+
+	var (
+		game  database.Game
+		seats []SeatResponse
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, err = lockGame(ctx, q, c.Param("code"))
+		if err != nil {
+			return err
+		}
+		if domain.GameStatus(game.Status) != domain.GameWaiting {
+			return errSeatsLocked
+		}
+
+		players, err := q.ListPlayersByGameCode(ctx, game.Code)
+		if err != nil {
+			return err
+		}
+		if len(req.PlayerIDs) != len(players) {
+			return errInvalidSeats
+		}
+
+		// 沿用原本的座位號碼，只換坐的人
+		unseated := make(map[int64]bool, len(players))
+		for _, p := range players {
+			unseated[p.ID] = true
+		}
+		params := database.UpdatePlayerSeatsParams{GameID: game.ID, Ids: req.PlayerIDs}
+		for i, id := range req.PlayerIDs {
+			if !unseated[id] {
+				return errInvalidSeats
+			}
+			delete(unseated, id)
+			params.Seats = append(params.Seats, players[i].Seat)
+			seats = append(seats, SeatResponse{PlayerID: id, Seat: players[i].Seat})
+		}
+		return q.UpdatePlayerSeats(ctx, params)
+	})
+	if err != nil {
+		respondError(c, h.logger, err, "failed to update seats")
+		return
+	}
+
+	// 💺 廣播新的座位順序
+	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
+		Type: "seats_updated",
+		Data: gin.H{
+			"seats": seats,
+		},
+	})
+
+	Success(c, seats)
+}
+
+// pickNext decides who draws after lastRound under the game's turn order.
+// When the order leaves the choice to a player (see turnPicker), pickID is
+// required and only the host or that player may start the round; otherwise
+// pickID must be 0 and the host starts it. A callerID of 0 skips the check.
+func (h *RoundsHandler) pickNext(ctx context.Context, q database.Store, game database.Game, lastRound database.Round, players []database.ListPlayersByGameCodeRow, callerID, pickID int64) (int64, error) {
+	picker := turnPicker(game, lastRound, players)
+	if callerID != 0 {
+		caller, err := activePlayer(ctx, q, game.ID, callerID)
+		if err != nil {
+			return 0, err
+		}
+		if !caller.IsHost.Bool && caller.ID != picker {
+			return 0, errForbidden
+		}
+	}
+
+	if picker != 0 {
+		if pickID == 0 {
+			return 0, domain.ErrPickRequired
+		}
+		if _, err := activePlayer(ctx, q, game.ID, pickID); err != nil {
+			return 0, err
+		}
+		return pickID, nil
+	}
+	if pickID != 0 {
+		return 0, domain.ErrPickNotAllowed
+	}
+
+	seats := seatsOf(players)
+	if domain.TurnOrder(game.TurnOrder) == domain.TurnRandom {
+		drawers, err := q.ListRoundDrawers(ctx, game.ID)
+		if err != nil {
+			return 0, err
+		}
+		open := domain.OpenSeats(seats, drawers)
+		return open[h.rng.Intn(len(open))].PlayerID, nil
+	}
+
+	// 依座位決定下一位玩家；上一位離開了也從他的座位往下數
+	var lastSeat int32
+	if lastRound.ID != 0 {
+		drawer, err := q.GetPlayerInGame(ctx, database.GetPlayerInGameParams{
+			ID:     lastRound.CurrentPlayerID,
+			GameID: game.ID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		lastSeat = drawer.Seat
+	}
+	next, _ := domain.NextSeat(seats, lastSeat)
+	return next.PlayerID, nil
+}
+
+// turnPicker returns the player who picks who draws after lastRound, or 0
+// when the turn order picks by itself: the host in host_assigned games, and
+// in loser_picks games the drawer of a joker who is still playing.
+func turnPicker(game database.Game, lastRound database.Round, players []database.ListPlayersByGameCodeRow) int64 {
+	switch domain.TurnOrder(game.TurnOrder) {
+	case domain.TurnHostAssigned:
+		for _, p := range players {
+			if p.IsHost.Bool {
+				return p.ID
+			}
+		}
+	case domain.TurnLoserPicks:
+		if lastRound.ID == 0 || !lastRound.IsJoker.Bool {
+			return 0
+		}
+		for _, p := range players {
+			if p.ID == lastRound.CurrentPlayerID {
+				return p.ID
+			}
+		}
+	}
+	return 0
+}
+
+// seatsOf lists the players' seats in the order given.
+func seatsOf(players []database.ListPlayersByGameCodeRow) []domain.Seat {
+	seats := make([]domain.Seat, 0, len(players))
+	for _, p := range players {
+		seats = append(seats, domain.Seat{PlayerID: p.ID, Number: p.Seat})
+	}
+	return seats
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/auth"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+// turnTestGame starts a game with the given turn order and enough questions
+// for a dozen rounds. Players are returned in seat order.
+func turnTestGame(t *testing.T, store database.Store, order domain.TurnOrder, players int, edit func(*database.CreateGameParams)) (database.Game, []int64) {
+	t.Helper()
+	var questions []string
+	for i := range 12 {
+		questions = append(questions, fmt.Sprintf("turn %s %d", order, i))
+	}
+	game, rows := startTestGameWith(t, store, func(p *database.CreateGameParams) {
+		p.TurnOrder = string(order)
+		if edit != nil {
+			edit(p)
+		}
+	}, players, questions...)
+
+	var ids []int64
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	return game, ids
+}
+
+// playRound starts the next round and draws its card, returning the drawer.
+func playRound(t *testing.T, h *RoundsHandler, game database.Game, callerID, pickID int64) int64 {
+	t.Helper()
+	ctx := context.Background()
+	round, err := h.nextRound(ctx, game.Code, callerID, pickID)
+	if err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+	if _, err := h.draw(ctx, game.Code, round.ID, false); err != nil {
+		t.Fatalf("draw: %v", err)
+	}
+	return round.CurrentPlayerID
+}
+
+func TestSequentialTurns(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newTestRoundsHandler(t, store)
+			game, ids := turnTestGame(t, store, domain.TurnSequential, 3, nil)
+
+			var got []int64
+			for range 4 {
+				got = append(got, playRound(t, h, game, 0, 0))
+			}
+			if want := []int64{ids[0], ids[1], ids[2], ids[0]}; !slices.Equal(got, want) {
+				t.Fatalf("drawers %v, want %v", got, want)
+			}
+
+			// 輪到的人離開了，從他的座位往下數
+			if err := h.removePlayer(context.Background(), game.Code, ids[1]); err != nil {
+				t.Fatal(err)
+			}
+			got = nil
+			for range 3 {
+				got = append(got, playRound(t, h, game, 0, 0))
+			}
+			if want := []int64{ids[2], ids[0], ids[2]}; !slices.Equal(got, want) {
+				t.Fatalf("drawers after a player left %v, want %v", got, want)
+			}
+
+			// 自動輪替時不能指定人
+			if _, err := h.nextRound(context.Background(), game.Code, 0, ids[0]); !errors.Is(err, domain.ErrPickNotAllowed) {
+				t.Fatalf("pick in sequential order: got %v, want ErrPickNotAllowed", err)
+			}
+		})
+	}
+}
+
+func TestRandomTurns(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			h := newTestRoundsHandler(t, store)
+			game, ids := turnTestGame(t, store, domain.TurnRandom, 3, nil)
+
+			// 每一輪每個人剛好抽一次，換輪時也不會連抽
+			var drawers []int64
+			for cycle := range 3 {
+				var got []int64
+				for range len(ids) {
+					drawer := playRound(t, h, game, 0, 0)
+					if len(drawers) > 0 && drawers[len(drawers)-1] == drawer {
+						t.Fatalf("player %d drew twice in a row: %v", drawer, append(drawers, drawer))
+					}
+					drawers = append(drawers, drawer)
+					got = append(got, drawer)
+				}
+				slices.Sort(got)
+				if !slices.Equal(got, ids) {
+					t.Fatalf("cycle %d drawers %v, want each of %v once", cycle, got, ids)
+				}
+			}
+		})
+	}
+}
+
+func TestHostAssignedTurns(t *testing.T) {
+	ctx := context.Background()
+	store := testStores(t)["memory"]
+	h := newTestRoundsHandler(t, store)
+	game, ids := turnTestGame(t, store, domain.TurnHostAssigned, 3, nil)
+	host := ids[0]
+
+	if _, err := h.nextRound(ctx, game.Code, host, 0); !errors.Is(err, domain.ErrPickRequired) {
+		t.Fatalf("no pick: got %v, want ErrPickRequired", err)
+	}
+	if _, err := h.nextRound(ctx, game.Code, ids[1], ids[2]); !errors.Is(err, errForbidden) {
+		t.Fatalf("pick by a player: got %v, want errForbidden", err)
+	}
+	if _, err := h.nextRound(ctx, game.Code, host, 999); !errors.Is(err, errPlayerNotInGame) {
+		t.Fatalf("pick of a stranger: got %v, want errPlayerNotInGame", err)
+	}
+
+	// 主持人可以一直指定同一個人
+	for range 2 {
+		if got := playRound(t, h, game, host, ids[2]); got != ids[2] {
+			t.Fatalf("drawer %d, want the picked %d", got, ids[2])
+		}
+	}
+
+	// 計時器到期時沒有人挑，就停在需要指定的狀態
+	if _, err := h.nextRound(ctx, game.Code, 0, 0); !errors.Is(err, domain.ErrPickRequired) {
+		t.Fatalf("timer start: got %v, want ErrPickRequired", err)
+	}
+}
+
+func TestLoserPicksTurns(t *testing.T) {
+	ctx := context.Background()
+	store := testStores(t)["memory"]
+	h := newTestRoundsHandler(t, store)
+	// 每張都是鬼牌，抽到的人都要挑下一位
+	game, ids := turnTestGame(t, store, domain.TurnLoserPicks, 3, func(p *database.CreateGameParams) {
+		p.JokerProbability = 1
+	})
+
+	// 第一回合沒有輸家，照座位
+	if got := playRound(t, h, game, ids[0], 0); got != ids[0] {
+		t.Fatalf("first drawer %d, want seat one %d", got, ids[0])
+	}
+	if _, err := h.nextRound(ctx, game.Code, ids[0], 0); !errors.Is(err, domain.ErrPickRequired) {
+		t.Fatalf("loser without a pick: got %v, want ErrPickRequired", err)
+	}
+	if _, err := h.nextRound(ctx, game.Code, ids[1], ids[2]); !errors.Is(err, errForbidden) {
+		t.Fatalf("pick by someone else: got %v, want errForbidden", err)
+	}
+	if got := playRound(t, h, game, ids[0], ids[2]); got != ids[2] {
+		t.Fatalf("drawer %d, want the loser's pick %d", got, ids[2])
+	}
+
+	// 主持人也可以替輸家挑
+	if got := playRound(t, h, game, ids[0], ids[1]); got != ids[1] {
+		t.Fatalf("drawer %d, want the host's pick %d", got, ids[1])
+	}
+
+	// 輸家離開了就照座位往下
+	if err := h.removePlayer(ctx, game.Code, ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := playRound(t, h, game, ids[0], 0); got != ids[2] {
+		t.Fatalf("drawer %d after the loser left, want next seat %d", got, ids[2])
+	}
+}
+
+func TestSetSeats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := testStores(t)["memory"]
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rounds := newTestRoundsHandler(t, store)
+	players := NewPlayersHandler(store, logger, rounds.hub, auth.NewTokenManager([]byte("seats"), time.Hour, utils.SystemClock{}))
+
+	setSeats := func(code string, ids []int64) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(SetSeatsRequest{PlayerIDs: ids})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(body))
+		c.Params = gin.Params{{Key: "code", Value: code}}
+		players.SetSeats(c)
+		return w
+	}
+
+	game, ids := turnTestGame(t, store, domain.TurnSequential, 3, func(p *database.CreateGameParams) {
+		p.Status = string(domain.GameWaiting)
+	})
+
+	tests := []struct {
+		name string
+		ids  []int64
+		want int
+	}{
+		{"missing player", []int64{ids[0], ids[1]}, http.StatusBadRequest},
+		{"duplicate player", []int64{ids[0], ids[1], ids[1]}, http.StatusBadRequest},
+		{"unknown player", []int64{ids[0], ids[1], 999}, http.StatusBadRequest},
+		{"reversed", []int64{ids[2], ids[1], ids[0]}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := setSeats(game.Code, tt.ids); w.Code != tt.want {
+			t.Fatalf("%s: status %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+
+	// 開始之後照新的座位輪
+	if err := store.UpdateGameStatus(ctx, database.UpdateGameStatusParams{ID: game.ID, Status: string(domain.GamePlaying)}); err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for range 3 {
+		got = append(got, playRound(t, rounds, game, 0, 0))
+	}
+	if want := []int64{ids[2], ids[1], ids[0]}; !slices.Equal(got, want) {
+		t.Fatalf("drawers %v, want the new seat order %v", got, want)
+	}
+
+	// 開始之後就不能換座位
+	if w := setSeats(game.Code, []int64{ids[0], ids[1], ids[2]}); w.Code != http.StatusConflict {
+		t.Fatalf("seats of a started game: status %d, want 409", w.Code)
+	}
+}
@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/utils"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+type VoteRequest struct {
+	Accept *bool `json:"accept" binding:"required"`
+}
+
+// VoteResult is the state of the vote after an answer or a ballot. Result
+// stays empty until the vote is decided.
+type VoteResult struct {
+	RoundID int64             `json:"roundId"`
+	Attempt int32             `json:"attempt"`
+	Tally   domain.Tally      `json:"tally"`
+	Result  domain.VoteResult `json:"result,omitempty"`
+}
+
+// AnswerRound is called by the drawer once they have answered out loud. It
+// opens the vote on their answer.
+func (h *RoundsHandler) AnswerRound(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	roundID, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid round id")
+		return
+	}
+
+	result, err := h.answerRound(ctx, c.Param("code"), roundID)
+	if err != nil {
+		respondError(c, h.logger, err, "failed to answer round")
+		return
+	}
+
+	Success(c, result)
+}
+
+func (h *RoundsHandler) Vote(c *gin.Context) {
+	ctx := c.Request.Context()
+	player := playerFrom(c)
+
+	roundID, err := utils.ParseID(c.Param("id"))
+	if err != nil {
+		BadRequest(c, "invalid round id")
+		return
+	}
+
+	var req VoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "accept is required")
+		return
+	}
+
+	result, err := h.vote(ctx, c.Param("code"), roundID, player.ID, *req.Accept)
+	if err != nil {
+		respondError(c, h.logger, err, "failed to vote")
+		return
+	}
+
+	Success(c, result)
+}
+
+// answerRound moves a pending round into voting. With nobody else to vote
+// the answer is accepted at once.
+func (h *RoundsHandler) answerRound(ctx context.Context, gameCode string, roundID int64) (VoteResult, error) {
+	var (
+		game   database.Game
+		round  database.Round
+		result VoteResult
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, round, err = lockRound(ctx, q, gameCode, roundID)
+		if err != nil {
+			return err
+		}
+
+		if !domain.VoteRule(game.VoteRule).Enabled() {
+			return domain.ErrVotingOff
+		}
+		if err := domain.RoundStatus(round.Status).TransitionTo(domain.RoundVoting); err != nil {
+			return err
+		}
+
+		round.Status = string(domain.RoundVoting)
+		result, err = h.tally(ctx, q, game, &round)
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// 🗳 廣播開始投票
+	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
+		Type: "voting_started",
+		Data: gin.H{
+			"roundId":  round.ID,
+			"playerId": round.CurrentPlayerID,
+			"attempt":  result.Attempt,
+			"tally":    result.Tally,
+		},
+	})
+	h.announceVoteResult(game, round, result)
+	return result, nil
+}
+
+// vote records or changes a ballot on the drawer's answer. The drawer
+// cannot vote on their own answer.
+func (h *RoundsHandler) vote(ctx context.Context, gameCode string, roundID, voterID int64, accept bool) (VoteResult, error) {
+	var (
+		game   database.Game
+		round  database.Round
+		result VoteResult
+	)
+	err := h.store.ExecTx(ctx, func(q database.Store) error {
+		var err error
+		game, round, err = lockRound(ctx, q, gameCode, roundID)
+		if err != nil {
+			return err
+		}
+
+		if round.CurrentPlayerID == voterID {
+			return errForbidden
+		}
+		if round.Status != string(domain.RoundVoting) {
+			return errNotVoting
+		}
+
+		err = q.UpsertRoundVote(ctx, database.UpsertRoundVoteParams{
+			RoundID: round.ID,
+			VoterID: voterID,
+			Attempt: round.Attempt,
+			Accept:  accept,
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = h.tally(ctx, q, game, &round)
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	h.announceTally(game, round, result)
+	return result, nil
+}
+
+// tally counts the votes on the round's current answer and, once the vote is
+// decided, moves the round on: to answered when accepted, to answered with a
+// penalty draw or back to pending for a re-answer when rejected. It stores
+// the round's new phase and updates round to match.
+func (h *RoundsHandler) tally(ctx context.Context, q database.Store, game database.Game, round *database.Round) (VoteResult, error) {
+	result := VoteResult{RoundID: round.ID, Attempt: round.Attempt}
+
+	var err error
+	result.Tally, err = countVotes(ctx, q, *round)
+	if err != nil {
+		return result, err
+	}
+	result.Result = result.Tally.Result()
+
+	phase := database.UpdateRoundPhaseParams{
+		ID:      round.ID,
+		Status:  round.Status,
+		Attempt: round.Attempt,
+		Penalty: round.Penalty,
+	}
+	switch {
+	case result.Result == domain.VoteAccepted:
+		phase.Status = string(domain.RoundAnswered)
+	case result.Result == domain.VoteRejected && domain.VoteRule(game.VoteRule) == domain.VoteReanswer:
+		phase.Status = string(domain.RoundPending)
+		phase.Attempt++
+	case result.Result == domain.VoteRejected:
+		phase.Status = string(domain.RoundAnswered)
+		phase.Penalty = true
+	}
+
+	if err := q.UpdateRoundPhase(ctx, phase); err != nil {
+		return result, err
+	}
+	round.Status, round.Attempt, round.Penalty = phase.Status, phase.Attempt, phase.Penalty
+	return result, nil
+}
+
+// countVotes tallies the votes on the round's current answer. Every player
+// but the drawer may vote.
+func countVotes(ctx context.Context, q database.Store, round database.Round) (domain.Tally, error) {
+	players, err := q.CountPlayersInGame(ctx, round.GameID)
+	if err != nil {
+		return domain.Tally{}, err
+	}
+	votes, err := q.CountRoundVotes(ctx, database.CountRoundVotesParams{
+		RoundID: round.ID,
+		Attempt: round.Attempt,
+	})
+	if err != nil {
+		return domain.Tally{}, err
+	}
+
+	return domain.Tally{
+		Accepts:  int(votes.Accepts),
+		Rejects:  int(votes.Rejects),
+		Eligible: max(0, int(players)-1),
+	}, nil
+}
+
+// announceTally sends the new tally, and the result once it is decided.
+func (h *RoundsHandler) announceTally(game database.Game, round database.Round, result VoteResult) {
+	// 📊 即時廣播票數
+	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
+		Type: "vote_tally",
+		Data: gin.H{
+			"roundId": round.ID,
+			"attempt": result.Attempt,
+			"tally":   result.Tally,
+		},
+	})
+	h.announceVoteResult(game, round, result)
+}
+
+// announceVoteResult tells the room how a decided vote ended and what the
+// drawer has to do next.
+func (h *RoundsHandler) announceVoteResult(game database.Game, round database.Round, result VoteResult) {
+	if result.Result == domain.VoteUndecided {
+		return
+	}
+
+	next := "draw"
+	if round.Status == string(domain.RoundPending) {
+		next = "reanswer"
+	}
+
+	// ✅❌ 廣播投票結果
+	h.hub.BroadcastToGame(game.Code, ws.WebSocketMessage{
+		Type: "vote_result",
+		Data: gin.H{
+			"roundId":  round.ID,
+			"playerId": round.CurrentPlayerID,
+			"attempt":  result.Attempt,
+			"result":   result.Result,
+			"tally":    result.Tally,
+			"penalty":  round.Penalty,
+			"next":     next,
+		},
+	})
+}
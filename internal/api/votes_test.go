@@ -0,0 +1,246 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/domain"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// watchGame registers a socket in the game's room on the handler's hub.
+func watchGame(h *RoundsHandler, gameCode string) *ws.Client {
+	client := &ws.Client{Send: make(chan []byte, 256), GameCode: gameCode, PlayerID: -1, Hub: h.hub}
+	h.hub.Register(client)
+	return client
+}
+
+// expectMessage skips messages until one of type typ arrives and decodes its
+// data into out.
+func expectMessage(t *testing.T, client *ws.Client, typ string, out any) {
+	t.Helper()
+	for {
+		select {
+		case payload := <-client.Send:
+			var msg struct {
+				Type string          `json:"type"`
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(payload, &msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Type != typ {
+				continue
+			}
+			if err := json.Unmarshal(msg.Data, out); err != nil {
+				t.Fatal(err)
+			}
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s message", typ)
+		}
+	}
+}
+
+// voteTestRound starts a round in a game with the given vote rule and
+// returns the drawer and the other players.
+func voteTestRound(t *testing.T, store database.Store, rule domain.VoteRule, players int) (*RoundsHandler, database.Game, database.CreateRoundRow, []int64) {
+	t.Helper()
+	game, _ := startTestGameWith(t, store, func(p *database.CreateGameParams) {
+		p.VoteRule = string(rule)
+	}, players, "vote a", "vote b")
+
+	h := newTestRoundsHandler(t, store)
+	round, err := h.nextRound(context.Background(), game.Code, 0, 0)
+	if err != nil {
+		t.Fatalf("start round: %v", err)
+	}
+	list, err := store.ListPlayersByGameCode(context.Background(), game.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var voters []int64
+	for _, p := range list {
+		if p.ID != round.CurrentPlayerID {
+			voters = append(voters, p.ID)
+		}
+	}
+	return h, game, round, voters
+}
+
+func roundPhase(t *testing.T, store database.Store, roundID int64) database.Round {
+	t.Helper()
+	round, err := store.GetRoundByID(context.Background(), roundID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return round
+}
+
+type tallyMessage struct {
+	RoundID int64        `json:"roundId"`
+	Attempt int32        `json:"attempt"`
+	Tally   domain.Tally `json:"tally"`
+}
+
+type resultMessage struct {
+	RoundID int64             `json:"roundId"`
+	Attempt int32             `json:"attempt"`
+	Result  domain.VoteResult `json:"result"`
+	Tally   domain.Tally      `json:"tally"`
+	Penalty bool              `json:"penalty"`
+	Next    string            `json:"next"`
+}
+
+func TestVoteTally(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			h, game, round, voters := voteTestRound(t, store, domain.VotePenalty, 4)
+			watcher := watchGame(h, game.Code)
+
+			if _, err := h.vote(ctx, game.Code, round.ID, voters[0], true); !errors.Is(err, errNotVoting) {
+				t.Fatalf("vote before the answer: got %v, want errNotVoting", err)
+			}
+
+			result, err := h.answerRound(ctx, game.Code, round.ID)
+			if err != nil {
+				t.Fatalf("answer: %v", err)
+			}
+			if result.Result != domain.VoteUndecided || result.Tally != (domain.Tally{Eligible: 3}) {
+				t.Fatalf("answer result %+v, want an open vote of 3", result)
+			}
+			var started tallyMessage
+			expectMessage(t, watcher, "voting_started", &started)
+			if started.RoundID != round.ID || started.Tally.Eligible != 3 {
+				t.Fatalf("voting_started %+v", started)
+			}
+
+			// 抽牌的人不能投自己
+			if _, err := h.vote(ctx, game.Code, round.ID, round.CurrentPlayerID, true); !errors.Is(err, errForbidden) {
+				t.Fatalf("drawer vote: got %v, want errForbidden", err)
+			}
+
+			// 每人一票，再投一次是改票
+			for _, accept := range []bool{true, false, true} {
+				if _, err := h.vote(ctx, game.Code, round.ID, voters[0], accept); err != nil {
+					t.Fatalf("vote: %v", err)
+				}
+				want := domain.Tally{Eligible: 3}
+				if accept {
+					want.Accepts = 1
+				} else {
+					want.Rejects = 1
+				}
+				var tally tallyMessage
+				expectMessage(t, watcher, "vote_tally", &tally)
+				if tally.RoundID != round.ID || tally.Tally != want {
+					t.Fatalf("vote_tally %+v, want %+v", tally, want)
+				}
+			}
+			if got := roundPhase(t, store, round.ID); got.Status != string(domain.RoundVoting) {
+				t.Fatalf("status %q with the vote open, want voting", got.Status)
+			}
+		})
+	}
+}
+
+func TestVoteResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        domain.VoteRule
+		ballots     []bool
+		want        domain.VoteResult
+		wantStatus  domain.RoundStatus
+		wantAttempt int32
+		wantPenalty bool
+		wantNext    string
+	}{
+		{"majority accepts", domain.VotePenalty, []bool{true, true}, domain.VoteAccepted, domain.RoundAnswered, 1, false, "draw"},
+		{"majority rejects with penalty", domain.VotePenalty, []bool{false, false}, domain.VoteRejected, domain.RoundAnswered, 1, true, "draw"},
+		{"majority rejects with reanswer", domain.VoteReanswer, []bool{false, false}, domain.VoteRejected, domain.RoundPending, 2, false, "reanswer"},
+		{"unanimous accept", domain.VoteReanswer, []bool{true, true}, domain.VoteAccepted, domain.RoundAnswered, 1, false, "draw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := testStores(t)["memory"]
+			h, game, round, voters := voteTestRound(t, store, tt.rule, 4)
+			watcher := watchGame(h, game.Code)
+
+			if _, err := h.answerRound(ctx, game.Code, round.ID); err != nil {
+				t.Fatalf("answer: %v", err)
+			}
+			var result VoteResult
+			for i, accept := range tt.ballots {
+				var err error
+				result, err = h.vote(ctx, game.Code, round.ID, voters[i], accept)
+				if err != nil {
+					t.Fatalf("vote %d: %v", i, err)
+				}
+			}
+			if result.Result != tt.want {
+				t.Fatalf("result %q, want %q", result.Result, tt.want)
+			}
+
+			var msg resultMessage
+			expectMessage(t, watcher, "vote_result", &msg)
+			if msg.Result != tt.want || msg.Penalty != tt.wantPenalty || msg.Next != tt.wantNext {
+				t.Fatalf("vote_result %+v", msg)
+			}
+
+			got := roundPhase(t, store, round.ID)
+			if got.Status != string(tt.wantStatus) || got.Attempt != tt.wantAttempt || got.Penalty != tt.wantPenalty {
+				t.Fatalf("round %s attempt %d penalty %v; want %s, %d, %v",
+					got.Status, got.Attempt, got.Penalty, tt.wantStatus, tt.wantAttempt, tt.wantPenalty)
+			}
+
+			// 投票結束後多出來的票不算
+			_, err := h.vote(ctx, game.Code, round.ID, voters[2], true)
+			if tt.wantStatus == domain.RoundPending {
+				// 重新作答後才會再開票，票數從新的一輪算起
+				if !errors.Is(err, errNotVoting) {
+					t.Fatalf("late vote: got %v, want errNotVoting", err)
+				}
+				result, err := h.answerRound(ctx, game.Code, round.ID)
+				if err != nil {
+					t.Fatalf("answer again: %v", err)
+				}
+				if result.Attempt != 2 || result.Tally != (domain.Tally{Eligible: 3}) {
+					t.Fatalf("second attempt %+v, want a fresh tally", result)
+				}
+				return
+			}
+			if !errors.Is(err, errNotVoting) {
+				t.Fatalf("late vote: got %v, want errNotVoting", err)
+			}
+		})
+	}
+}
+
+func TestAnswerRoundWithoutVoters(t *testing.T) {
+	ctx := context.Background()
+	store := testStores(t)["memory"]
+
+	h, game, round, _ := voteTestRound(t, store, domain.VoteOff, 2)
+	if _, err := h.answerRound(ctx, game.Code, round.ID); !errors.Is(err, domain.ErrVotingOff) {
+		t.Fatalf("voting off: got %v, want ErrVotingOff", err)
+	}
+
+	// 只有抽牌的人在場時，沒人能投票，答案直接通過
+	h, game, round, _ = voteTestRound(t, store, domain.VotePenalty, 1)
+	result, err := h.answerRound(ctx, game.Code, round.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Result != domain.VoteAccepted {
+		t.Fatalf("result %q, want accepted", result.Result)
+	}
+	if got := roundPhase(t, store, round.ID); got.Status != string(domain.RoundAnswered) {
+		t.Fatalf("status %q, want answered", got.Status)
+	}
+}
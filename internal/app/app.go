@@ -2,33 +2,55 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/y3933y3933/joker/internal/api"
+	"github.com/y3933y3933/joker/internal/auth"
 	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/utils"
 	"github.com/y3933y3933/joker/internal/ws"
 )
 
 type config struct {
-	Port   int
-	Env    string
-	DB_URL string
+	Port        int
+	Env         string
+	DB_URL      string
+	Store       string
+	TokenSecret string
+	TokenTTL    time.Duration
+	WS          ws.Config
+	Backplane   string
+	AdminToken  string
+	TimerTick   time.Duration
 }
 
 type Application struct {
-	Logger         *slog.Logger
-	DBQueries      *database.Queries
-	DB             *pgxpool.Pool
-	Config         config
-	GamesHandler   *api.GamesHandler
-	PlayersHandler *api.PlayersHandler
-	RoundsHandler  *api.RoundsHandler
-	WSHub          *ws.Hub
+	Logger           *slog.Logger
+	Store            database.Store
+	DB               *pgxpool.Pool
+	Config           config
+	GamesHandler     *api.GamesHandler
+	PlayersHandler   *api.PlayersHandler
+	RoundsHandler    *api.RoundsHandler
+	StateHandler     *api.StateHandler
+	QuestionsHandler *api.QuestionsHandler
+	DecksHandler     *api.DecksHandler
+	WSHub            *ws.Hub
+	WSHandler        *ws.Handler
+	Tokens           *auth.TokenManager
+	Authorizer       *api.Authorizer
+	stopBackplane    context.CancelFunc
+	stopTimers       context.CancelFunc
 }
 
 func NewApplication() (*Application, error) {
@@ -37,47 +59,168 @@ func NewApplication() (*Application, error) {
 	flag.IntVar(&cfg.Port, "port", 8080, "API server port")
 	flag.StringVar(&cfg.Env, "env", "dev", "Environment (dev|prod)")
 	flag.StringVar(&cfg.DB_URL, "db-url", "", "DATABASE URL")
+	flag.StringVar(&cfg.Store, "store", "postgres", "Storage backend (postgres|memory)")
+	flag.StringVar(&cfg.TokenSecret, "token-secret", os.Getenv("TOKEN_SECRET"), "Secret used to sign player session tokens")
+	flag.DurationVar(&cfg.TokenTTL, "token-ttl", 24*time.Hour, "Player session token lifetime")
+	flag.StringVar(&cfg.AdminToken, "admin-token", os.Getenv("ADMIN_TOKEN"), "Bearer token for the admin API; empty disables it")
+	flag.DurationVar(&cfg.TimerTick, "round-timer-tick", 5*time.Second, "Interval between timer_tick events of timed rounds; 0 disables them")
+
+	wsDefaults := ws.DefaultConfig()
+	flag.DurationVar(&cfg.WS.PingPeriod, "ws-ping-period", wsDefaults.PingPeriod, "Interval between WebSocket pings")
+	flag.DurationVar(&cfg.WS.PongWait, "ws-pong-wait", wsDefaults.PongWait, "How long to wait for a pong before dropping a WebSocket")
+	flag.DurationVar(&cfg.WS.WriteWait, "ws-write-wait", wsDefaults.WriteWait, "WebSocket write deadline")
+	flag.Int64Var(&cfg.WS.MaxMessageSize, "ws-max-message-size", wsDefaults.MaxMessageSize, "Largest inbound WebSocket message in bytes")
+	flag.IntVar(&cfg.WS.ReplayBufferSize, "ws-replay-buffer", wsDefaults.ReplayBufferSize, "Messages kept per room for reconnect replay")
+	flag.DurationVar(&cfg.WS.RoomIdleTTL, "ws-room-idle-ttl", wsDefaults.RoomIdleTTL, "How long an empty room keeps its replay buffer")
+	flag.StringVar(&cfg.Backplane, "ws-backplane", "local", "WebSocket fan-out between replicas (local|postgres)")
 
 	flag.Parse()
 
 	loggerHandler := slog.NewTextHandler(os.Stdout, nil)
 	logger := slog.New(loggerHandler)
 
-	dbpool, err := pgxpool.New(context.Background(), cfg.DB_URL)
-	if err != nil {
-		logger.Error("Unable to create connection pool:", err)
-		os.Exit(1)
+	// 遊戲邏輯的隨機與時間都從這裡注入，測試可換成固定的實作
+	var (
+		rng   utils.Random = utils.NewCryptoRandom()
+		clock utils.Clock  = utils.SystemClock{}
+	)
+
+	var (
+		dbpool *pgxpool.Pool
+		store  database.Store
+		err    error
+	)
+	switch cfg.Store {
+	case "memory":
+		logger.Warn("using in-memory store; data is lost on restart")
+		store = memory.New(clock)
+	case "postgres":
+		dbpool, err = pgxpool.New(context.Background(), cfg.DB_URL)
+		if err != nil {
+			logger.Error("Unable to create connection pool", "error", err)
+			os.Exit(1)
+		}
+		err = dbpool.Ping(context.Background())
+		if err != nil {
+			logger.Error("Unable to create connection pool", "error", err)
+			os.Exit(1)
+		}
+		store = database.New(dbpool)
+	default:
+		return nil, fmt.Errorf("unknown store %q", cfg.Store)
 	}
-	err = dbpool.Ping(context.Background())
+
+	secret, err := tokenSecret(cfg)
 	if err != nil {
-		logger.Error("Unable to create connection pool:", err)
-		os.Exit(1)
+		return nil, err
+	}
+	if cfg.TokenSecret == "" {
+		logger.Warn("no -token-secret given, using a random one; sessions will not survive a restart")
+	}
+	tokens := auth.NewTokenManager(secret, cfg.TokenTTL, clock)
+	if cfg.AdminToken == "" {
+		logger.Warn("no -admin-token given, admin API is disabled")
 	}
 
-	queries := database.New(dbpool)
+	if cfg.WS.PingPeriod >= cfg.WS.PongWait {
+		return nil, errors.New("ws-ping-period must be shorter than ws-pong-wait")
+	}
+	if cfg.WS.RoomIdleTTL <= 0 {
+		return nil, errors.New("ws-room-idle-ttl must be positive")
+	}
+	if cfg.TimerTick < 0 {
+		return nil, errors.New("round-timer-tick must not be negative")
+	}
 
-	hub := ws.NewHub()
-	go hub.Run()
+	var (
+		backplane     ws.Backplane
+		stopBackplane context.CancelFunc = func() {}
+	)
+	switch cfg.Backplane {
+	case "local":
+		backplane = ws.NewLocalBackplane()
+	case "postgres":
+		if dbpool == nil {
+			return nil, errors.New("ws-backplane postgres requires the postgres store")
+		}
+		pg := ws.NewPostgresBackplane(dbpool, "ghostcard_ws", logger)
+		var listenCtx context.Context
+		listenCtx, stopBackplane = context.WithCancel(context.Background())
+		go pg.Listen(listenCtx)
+		backplane = pg
+	default:
+		return nil, fmt.Errorf("unknown ws backplane %q", cfg.Backplane)
+	}
+
+	hub := ws.NewHub(cfg.WS, backplane)
 
 	// handler
-	gamesHandler := api.NewGamesHandler(queries, logger)
-	playersHandler := api.NewPlayersHandler(queries, logger, hub)
-	roundsHandler := api.NewRoundsHandler(queries, logger, hub)
+	gamesHandler := api.NewGamesHandler(store, logger, rng, clock)
+	playersHandler := api.NewPlayersHandler(store, logger, hub, tokens)
+	roundsHandler := api.NewRoundsHandler(store, logger, hub, rng, clock)
+	stateHandler := api.NewStateHandler(store, logger, hub, clock)
+	questionsHandler := api.NewQuestionsHandler(store, logger, clock)
+	decksHandler := api.NewDecksHandler(store, logger)
+	authorizer := api.NewAuthorizer(store, logger)
+	commandHandler := api.NewCommandHandler(roundsHandler, authorizer, logger)
+
+	go hub.Run()
+
+	// 重啟後從資料庫接回還在倒數的回合
+	if err := roundsHandler.RestoreTimers(context.Background()); err != nil {
+		hub.Stop()
+		stopBackplane()
+		return nil, fmt.Errorf("restore round timers: %w", err)
+	}
+	timersCtx, stopTimers := context.WithCancel(context.Background())
+	go roundsHandler.RunTimers(timersCtx, cfg.TimerTick)
+
+	wsHandler := &ws.Handler{
+		Hub:     hub,
+		Session: api.SessionFrom,
+		Snapshot: func(ctx context.Context, gameCode string, playerID int64) (any, error) {
+			return stateHandler.Snapshot(ctx, gameCode, playerID)
+		},
+		Commands: commandHandler.Handle,
+	}
 
 	app := &Application{
-		Logger:         logger,
-		DB:             dbpool,
-		DBQueries:      queries,
-		Config:         cfg,
-		GamesHandler:   gamesHandler,
-		PlayersHandler: playersHandler,
-		RoundsHandler:  roundsHandler,
-		WSHub:          hub,
+		Logger:           logger,
+		DB:               dbpool,
+		Store:            store,
+		Config:           cfg,
+		GamesHandler:     gamesHandler,
+		PlayersHandler:   playersHandler,
+		RoundsHandler:    roundsHandler,
+		StateHandler:     stateHandler,
+		QuestionsHandler: questionsHandler,
+		DecksHandler:     decksHandler,
+		WSHub:            hub,
+		WSHandler:        wsHandler,
+		Tokens:           tokens,
+		Authorizer:       authorizer,
+		stopBackplane:    stopBackplane,
+		stopTimers:       stopTimers,
 	}
 
 	return app, nil
 }
 
+func tokenSecret(cfg config) ([]byte, error) {
+	if cfg.TokenSecret != "" {
+		return []byte(cfg.TokenSecret), nil
+	}
+	if cfg.Env == "prod" {
+		return nil, errors.New("token secret is required in prod")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
 func (app *Application) HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status":  "available",
@@ -87,5 +230,10 @@ func (app *Application) HealthCheck(c *gin.Context) {
 }
 
 func (app *Application) Close() {
-	app.DB.Close()
+	app.stopTimers()
+	app.WSHub.Stop()
+	app.stopBackplane()
+	if app.DB != nil {
+		app.DB.Close()
+	}
 }
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid session token")
+	ErrExpiredToken = errors.New("session token expired")
+)
+
+// Claims identify a player session inside a single game.
+type Claims struct {
+	PlayerID  int64  `json:"pid"`
+	GameID    int64  `json:"gid"`
+	GameCode  string `json:"code"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// TokenManager issues and verifies HMAC-signed player session tokens.
+type TokenManager struct {
+	secret []byte
+	ttl    time.Duration
+	clock  utils.Clock
+}
+
+func NewTokenManager(secret []byte, ttl time.Duration, clock utils.Clock) *TokenManager {
+	return &TokenManager{
+		secret: secret,
+		ttl:    ttl,
+		clock:  clock,
+	}
+}
+
+// Issue returns a token of the form base64(claims).base64(signature).
+func (m *TokenManager) Issue(playerID, gameID int64, gameCode string) (string, error) {
+	claims := Claims{
+		PlayerID:  playerID,
+		GameID:    gameID,
+		GameCode:  gameCode,
+		ExpiresAt: m.clock.Now().Add(m.ttl).Unix(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(m.sign(encoded)), nil
+}
+
+func (m *TokenManager) Verify(token string) (Claims, error) {
+	var claims Claims
+
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return claims, ErrInvalidToken
+	}
+
+	gotSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotSig, m.sign(encoded)) {
+		return claims, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return claims, ErrInvalidToken
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, ErrInvalidToken
+	}
+
+	if m.clock.Now().Unix() > claims.ExpiresAt {
+		return claims, ErrExpiredToken
+	}
+
+	return claims, nil
+}
+
+func (m *TokenManager) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -7,22 +7,61 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createGame = `-- name: CreateGame :one
-INSERT INTO games (code, level, status)
-VALUES ($1, $2, $3)
-RETURNING id, code, level, status, created_at, updated_at
+INSERT INTO games (
+    code, level, status, deck_id,
+    draw_mode, joker_probability, pity_draws, pile_safe_cards, pile_jokers,
+    answer_time_limit_seconds, timeout_action, vote_rule,
+    points_safe, points_joker, points_skipped, points_timed_out, turn_order
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+RETURNING id, code, level, status, created_at, updated_at, deck_id, question_cursor, draw_mode, joker_probability, pity_draws, pile_safe_cards, pile_jokers, draws_since_joker, pile_safe_left, pile_jokers_left, answer_time_limit_seconds, timeout_action, vote_rule, points_safe, points_joker, points_skipped, points_timed_out, turn_order
 `
 
 type CreateGameParams struct {
-	Code   string
-	Level  string
-	Status string
+	Code                   string
+	Level                  string
+	Status                 string
+	DeckID                 pgtype.Int8
+	DrawMode               string
+	JokerProbability       float64
+	PityDraws              int32
+	PileSafeCards          int32
+	PileJokers             int32
+	AnswerTimeLimitSeconds int32
+	TimeoutAction          string
+	VoteRule               string
+	PointsSafe             int32
+	PointsJoker            int32
+	PointsSkipped          int32
+	PointsTimedOut         int32
+	TurnOrder              string
 }
 
 func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) (Game, error) {
-	row := q.db.QueryRow(ctx, createGame, arg.Code, arg.Level, arg.Status)
+	row := q.db.QueryRow(ctx, createGame,
+		arg.Code,
+		arg.Level,
+		arg.Status,
+		arg.DeckID,
+		arg.DrawMode,
+		arg.JokerProbability,
+		arg.PityDraws,
+		arg.PileSafeCards,
+		arg.PileJokers,
+		arg.AnswerTimeLimitSeconds,
+		arg.TimeoutAction,
+		arg.VoteRule,
+		arg.PointsSafe,
+		arg.PointsJoker,
+		arg.PointsSkipped,
+		arg.PointsTimedOut,
+		arg.TurnOrder,
+	)
 	var i Game
 	err := row.Scan(
 		&i.ID,
@@ -31,12 +70,30 @@ func (q *Queries) CreateGame(ctx context.Context, arg CreateGameParams) (Game, e
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeckID,
+		&i.QuestionCursor,
+		&i.DrawMode,
+		&i.JokerProbability,
+		&i.PityDraws,
+		&i.PileSafeCards,
+		&i.PileJokers,
+		&i.DrawsSinceJoker,
+		&i.PileSafeLeft,
+		&i.PileJokersLeft,
+		&i.AnswerTimeLimitSeconds,
+		&i.TimeoutAction,
+		&i.VoteRule,
+		&i.PointsSafe,
+		&i.PointsJoker,
+		&i.PointsSkipped,
+		&i.PointsTimedOut,
+		&i.TurnOrder,
 	)
 	return i, err
 }
 
 const getGameByCode = `-- name: GetGameByCode :one
-SELECT id, code, level, status, created_at, updated_at FROM games
+SELECT id, code, level, status, created_at, updated_at, deck_id, question_cursor, draw_mode, joker_probability, pity_draws, pile_safe_cards, pile_jokers, draws_since_joker, pile_safe_left, pile_jokers_left, answer_time_limit_seconds, timeout_action, vote_rule, points_safe, points_joker, points_skipped, points_timed_out, turn_order FROM games
 WHERE code = $1
 `
 
@@ -50,10 +107,142 @@ func (q *Queries) GetGameByCode(ctx context.Context, code string) (Game, error)
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeckID,
+		&i.QuestionCursor,
+		&i.DrawMode,
+		&i.JokerProbability,
+		&i.PityDraws,
+		&i.PileSafeCards,
+		&i.PileJokers,
+		&i.DrawsSinceJoker,
+		&i.PileSafeLeft,
+		&i.PileJokersLeft,
+		&i.AnswerTimeLimitSeconds,
+		&i.TimeoutAction,
+		&i.VoteRule,
+		&i.PointsSafe,
+		&i.PointsJoker,
+		&i.PointsSkipped,
+		&i.PointsTimedOut,
+		&i.TurnOrder,
 	)
 	return i, err
 }
 
+const lockGameByCode = `-- name: LockGameByCode :one
+SELECT id, code, level, status, created_at, updated_at, deck_id, question_cursor, draw_mode, joker_probability, pity_draws, pile_safe_cards, pile_jokers, draws_since_joker, pile_safe_left, pile_jokers_left, answer_time_limit_seconds, timeout_action, vote_rule, points_safe, points_joker, points_skipped, points_timed_out, turn_order FROM games
+WHERE code = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockGameByCode(ctx context.Context, code string) (Game, error) {
+	row := q.db.QueryRow(ctx, lockGameByCode, code)
+	var i Game
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Level,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeckID,
+		&i.QuestionCursor,
+		&i.DrawMode,
+		&i.JokerProbability,
+		&i.PityDraws,
+		&i.PileSafeCards,
+		&i.PileJokers,
+		&i.DrawsSinceJoker,
+		&i.PileSafeLeft,
+		&i.PileJokersLeft,
+		&i.AnswerTimeLimitSeconds,
+		&i.TimeoutAction,
+		&i.VoteRule,
+		&i.PointsSafe,
+		&i.PointsJoker,
+		&i.PointsSkipped,
+		&i.PointsTimedOut,
+		&i.TurnOrder,
+	)
+	return i, err
+}
+
+const lockGameByCodeForShare = `-- name: LockGameByCodeForShare :one
+SELECT id, code, level, status, created_at, updated_at, deck_id, question_cursor, draw_mode, joker_probability, pity_draws, pile_safe_cards, pile_jokers, draws_since_joker, pile_safe_left, pile_jokers_left, answer_time_limit_seconds, timeout_action, vote_rule, points_safe, points_joker, points_skipped, points_timed_out, turn_order FROM games
+WHERE code = $1
+FOR SHARE
+`
+
+func (q *Queries) LockGameByCodeForShare(ctx context.Context, code string) (Game, error) {
+	row := q.db.QueryRow(ctx, lockGameByCodeForShare, code)
+	var i Game
+	err := row.Scan(
+		&i.ID,
+		&i.Code,
+		&i.Level,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeckID,
+		&i.QuestionCursor,
+		&i.DrawMode,
+		&i.JokerProbability,
+		&i.PityDraws,
+		&i.PileSafeCards,
+		&i.PileJokers,
+		&i.DrawsSinceJoker,
+		&i.PileSafeLeft,
+		&i.PileJokersLeft,
+		&i.AnswerTimeLimitSeconds,
+		&i.TimeoutAction,
+		&i.VoteRule,
+		&i.PointsSafe,
+		&i.PointsJoker,
+		&i.PointsSkipped,
+		&i.PointsTimedOut,
+		&i.TurnOrder,
+	)
+	return i, err
+}
+
+const updateGameDeck = `-- name: UpdateGameDeck :exec
+UPDATE games SET deck_id = $2, updated_at = $3 WHERE id = $1
+`
+
+type UpdateGameDeckParams struct {
+	ID        int64
+	DeckID    pgtype.Int8
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) UpdateGameDeck(ctx context.Context, arg UpdateGameDeckParams) error {
+	_, err := q.db.Exec(ctx, updateGameDeck, arg.ID, arg.DeckID, arg.UpdatedAt)
+	return err
+}
+
+const updateGameDrawState = `-- name: UpdateGameDrawState :exec
+UPDATE games
+SET draws_since_joker = $2, pile_safe_left = $3, pile_jokers_left = $4
+WHERE id = $1
+`
+
+type UpdateGameDrawStateParams struct {
+	ID              int64
+	DrawsSinceJoker int32
+	PileSafeLeft    int32
+	PileJokersLeft  int32
+}
+
+func (q *Queries) UpdateGameDrawState(ctx context.Context, arg UpdateGameDrawStateParams) error {
+	_, err := q.db.Exec(ctx, updateGameDrawState,
+		arg.ID,
+		arg.DrawsSinceJoker,
+		arg.PileSafeLeft,
+		arg.PileJokersLeft,
+	)
+	return err
+}
+
 const updateGameStatus = `-- name: UpdateGameStatus :exec
 UPDATE games SET status = $2 WHERE id = $1
 `
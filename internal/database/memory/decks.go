@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+func (s *Store) CreateDeck(ctx context.Context, arg database.CreateDeckParams) (database.Deck, error) {
+	defer s.lock()()
+
+	if _, ok := s.data.games[arg.GameID.Int64]; arg.GameID.Valid && !ok {
+		return database.Deck{}, errForeignKeyViolation("decks_game_id_fkey")
+	}
+
+	deck := database.Deck{
+		ID:        s.data.newID(),
+		Name:      arg.Name,
+		GameID:    arg.GameID,
+		CreatedAt: s.now(),
+		UpdatedAt: s.now(),
+	}
+	s.data.decks[deck.ID] = deck
+	return deck, nil
+}
+
+func (s *Store) GetPublicDeck(ctx context.Context, id int64) (database.Deck, error) {
+	defer s.lock()()
+
+	deck, ok := s.data.decks[id]
+	if !ok || deck.GameID.Valid {
+		return database.Deck{}, pgx.ErrNoRows
+	}
+	return deck, nil
+}
+
+func (s *Store) ListPublicDecks(ctx context.Context) ([]database.Deck, error) {
+	defer s.lock()()
+
+	var decks []database.Deck
+	for _, d := range s.data.decks {
+		if !d.GameID.Valid {
+			decks = append(decks, d)
+		}
+	}
+	slices.SortFunc(decks, func(a, b database.Deck) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return decks, nil
+}
+
+func (s *Store) AddQuestionToDeck(ctx context.Context, arg database.AddQuestionToDeckParams) error {
+	defer s.lock()()
+
+	if _, ok := s.data.decks[arg.DeckID]; !ok {
+		return errForeignKeyViolation("deck_questions_deck_id_fkey")
+	}
+	if _, ok := s.data.questions[arg.QuestionID]; !ok {
+		return errForeignKeyViolation("deck_questions_question_id_fkey")
+	}
+	s.data.deckItems[database.DeckQuestion{DeckID: arg.DeckID, QuestionID: arg.QuestionID}] = true
+	return nil
+}
+
+func (s *Store) RemoveQuestionFromDeck(ctx context.Context, arg database.RemoveQuestionFromDeckParams) (int64, error) {
+	defer s.lock()()
+
+	key := database.DeckQuestion{DeckID: arg.DeckID, QuestionID: arg.QuestionID}
+	if !s.data.deckItems[key] {
+		return 0, nil
+	}
+	delete(s.data.deckItems, key)
+	return 1, nil
+}
+
+func (s *Store) ListDeckQuestions(ctx context.Context, deckID int64) ([]database.Question, error) {
+	defer s.lock()()
+	return s.deckQuestions(deckID), nil
+}
+
+func (s *Store) ListDeckQuestionIDs(ctx context.Context, deckID int64) ([]int64, error) {
+	defer s.lock()()
+
+	var ids []int64
+	for _, q := range s.deckQuestions(deckID) {
+		ids = append(ids, q.ID)
+	}
+	return ids, nil
+}
+
+// deckQuestions returns the deck's questions that are not deleted, by id.
+func (s *Store) deckQuestions(deckID int64) []database.Question {
+	var questions []database.Question
+	for item := range s.data.deckItems {
+		if item.DeckID != deckID {
+			continue
+		}
+		if q := s.data.questions[item.QuestionID]; !q.DeletedAt.Valid {
+			questions = append(questions, q)
+		}
+	}
+	slices.SortFunc(questions, func(a, b database.Question) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return questions
+}
@@ -0,0 +1,36 @@
+package memory
+
+import "github.com/jackc/pgx/v5/pgconn"
+
+// errUniqueViolation mimics the error Postgres returns for a broken unique
+// constraint so callers can inspect it the same way.
+func errUniqueViolation(constraint string) error {
+	return &pgconn.PgError{
+		Code:           "23505",
+		Message:        "duplicate key value violates unique constraint",
+		ConstraintName: constraint,
+	}
+}
+
+func errForeignKeyViolation(constraint string) error {
+	return &pgconn.PgError{
+		Code:           "23503",
+		Message:        "update or delete violates foreign key constraint",
+		ConstraintName: constraint,
+	}
+}
+
+func errCheckViolation(constraint string) error {
+	return &pgconn.PgError{
+		Code:           "23514",
+		Message:        "new row violates check constraint",
+		ConstraintName: constraint,
+	}
+}
+
+func errValueTooLong() error {
+	return &pgconn.PgError{
+		Code:    "22001",
+		Message: "value too long for type character varying(100)",
+	}
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+func (s *Store) CreateGame(ctx context.Context, arg database.CreateGameParams) (database.Game, error) {
+	defer s.lock()()
+
+	if _, err := s.gameByCode(arg.Code); err == nil {
+		return database.Game{}, errUniqueViolation("games_code_key")
+	}
+	if arg.DeckID.Valid {
+		if _, ok := s.data.decks[arg.DeckID.Int64]; !ok {
+			return database.Game{}, errForeignKeyViolation("games_deck_id_fkey")
+		}
+	}
+
+	game := database.Game{
+		ID:                     s.data.newID(),
+		Code:                   arg.Code,
+		Level:                  arg.Level,
+		Status:                 arg.Status,
+		DeckID:                 arg.DeckID,
+		DrawMode:               arg.DrawMode,
+		JokerProbability:       arg.JokerProbability,
+		PityDraws:              arg.PityDraws,
+		PileSafeCards:          arg.PileSafeCards,
+		PileJokers:             arg.PileJokers,
+		CreatedAt:              s.now(),
+		UpdatedAt:              s.now(),
+		AnswerTimeLimitSeconds: arg.AnswerTimeLimitSeconds,
+		TimeoutAction:          arg.TimeoutAction,
+		VoteRule:               arg.VoteRule,
+		PointsSafe:             arg.PointsSafe,
+		PointsJoker:            arg.PointsJoker,
+		PointsSkipped:          arg.PointsSkipped,
+		PointsTimedOut:         arg.PointsTimedOut,
+		TurnOrder:              arg.TurnOrder,
+	}
+	s.data.games[game.ID] = game
+	return game, nil
+}
+
+func (s *Store) GetGameByCode(ctx context.Context, code string) (database.Game, error) {
+	defer s.lock()()
+	return s.gameByCode(code)
+}
+
+func (s *Store) LockGameByCode(ctx context.Context, code string) (database.Game, error) {
+	return s.GetGameByCode(ctx, code)
+}
+
+func (s *Store) LockGameByCodeForShare(ctx context.Context, code string) (database.Game, error) {
+	return s.GetGameByCode(ctx, code)
+}
+
+func (s *Store) UpdateGameStatus(ctx context.Context, arg database.UpdateGameStatusParams) error {
+	defer s.lock()()
+
+	if game, ok := s.data.games[arg.ID]; ok {
+		game.Status = arg.Status
+		s.data.games[arg.ID] = game
+	}
+	return nil
+}
+
+func (s *Store) UpdateGameDeck(ctx context.Context, arg database.UpdateGameDeckParams) error {
+	defer s.lock()()
+
+	if arg.DeckID.Valid {
+		if _, ok := s.data.decks[arg.DeckID.Int64]; !ok {
+			return errForeignKeyViolation("games_deck_id_fkey")
+		}
+	}
+	if game, ok := s.data.games[arg.ID]; ok {
+		game.DeckID = arg.DeckID
+		game.UpdatedAt = arg.UpdatedAt
+		s.data.games[arg.ID] = game
+	}
+	return nil
+}
+
+func (s *Store) UpdateGameDrawState(ctx context.Context, arg database.UpdateGameDrawStateParams) error {
+	defer s.lock()()
+
+	if game, ok := s.data.games[arg.ID]; ok {
+		game.DrawsSinceJoker = arg.DrawsSinceJoker
+		game.PileSafeLeft = arg.PileSafeLeft
+		game.PileJokersLeft = arg.PileJokersLeft
+		s.data.games[arg.ID] = game
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+func (s *Store) CreateRoundOutcome(ctx context.Context, arg database.CreateRoundOutcomeParams) error {
+	defer s.lock()()
+
+	if _, ok := s.data.rounds[arg.RoundID]; !ok {
+		return errForeignKeyViolation("round_outcomes_round_id_fkey")
+	}
+	if _, ok := s.data.players[arg.PlayerID]; !ok {
+		return errForeignKeyViolation("round_outcomes_player_id_fkey")
+	}
+	if _, ok := s.data.outcomes[arg.RoundID]; ok {
+		return errUniqueViolation("round_outcomes_pkey")
+	}
+
+	s.data.outcomes[arg.RoundID] = database.RoundOutcome{
+		RoundID:   arg.RoundID,
+		GameID:    arg.GameID,
+		PlayerID:  arg.PlayerID,
+		Outcome:   arg.Outcome,
+		Exposed:   arg.Exposed,
+		Points:    arg.Points,
+		CreatedAt: s.now(),
+	}
+	return nil
+}
+
+func (s *Store) ListScoreboard(ctx context.Context, gameID int64) ([]database.ListScoreboardRow, error) {
+	defer s.lock()()
+
+	rows := map[int64]*database.ListScoreboardRow{}
+	var items []database.ListScoreboardRow
+	for _, p := range s.playersInGame(gameID) {
+		items = append(items, database.ListScoreboardRow{
+			PlayerID: p.ID,
+			Nickname: p.Nickname,
+			Left:     p.LeftAt.Valid,
+		})
+	}
+	for i := range items {
+		rows[items[i].PlayerID] = &items[i]
+	}
+
+	for _, o := range s.data.outcomes {
+		row, ok := rows[o.PlayerID]
+		if !ok {
+			continue
+		}
+		row.Points += o.Points
+		row.Rounds++
+		if o.Exposed {
+			row.Jokers++
+		}
+		switch o.Outcome {
+		case "safe":
+			row.Safe++
+		case "skipped":
+			row.Skipped++
+		case "timed_out":
+			row.TimedOut++
+		}
+	}
+
+	slices.SortFunc(items, func(a, b database.ListScoreboardRow) int {
+		return cmp.Or(
+			cmp.Compare(b.Points, a.Points),
+			cmp.Compare(a.Jokers, b.Jokers),
+			cmp.Compare(a.PlayerID, b.PlayerID),
+		)
+	})
+	return items, nil
+}
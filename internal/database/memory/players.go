@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+func (s *Store) CountPlayersInGame(ctx context.Context, gameID int64) (int64, error) {
+	defer s.lock()()
+
+	var count int64
+	for _, p := range s.data.players {
+		if p.GameID == gameID && !p.LeftAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) CreatePlayer(ctx context.Context, arg database.CreatePlayerParams) (database.CreatePlayerRow, error) {
+	defer s.lock()()
+
+	if _, ok := s.data.games[arg.GameID]; !ok {
+		return database.CreatePlayerRow{}, errForeignKeyViolation("players_game_id_fkey")
+	}
+
+	var seat int32
+	for _, p := range s.data.players {
+		if p.GameID == arg.GameID {
+			seat = max(seat, p.Seat)
+		}
+	}
+
+	player := database.Player{
+		ID:       s.data.newID(),
+		GameID:   arg.GameID,
+		Nickname: arg.Nickname,
+		IsHost:   arg.IsHost,
+		JoinedAt: s.now(),
+		Seat:     seat + 1,
+	}
+	s.data.players[player.ID] = player
+
+	return database.CreatePlayerRow{
+		ID:       player.ID,
+		Nickname: player.Nickname,
+		IsHost:   player.IsHost,
+		JoinedAt: player.JoinedAt,
+		Seat:     player.Seat,
+	}, nil
+}
+
+func (s *Store) MarkPlayerLeft(ctx context.Context, arg database.MarkPlayerLeftParams) error {
+	defer s.lock()()
+
+	player, ok := s.data.players[arg.ID]
+	if !ok || player.GameID != arg.GameID || player.LeftAt.Valid {
+		return nil
+	}
+	player.LeftAt = arg.LeftAt
+	player.IsHost = pgtype.Bool{Bool: false, Valid: true}
+	s.data.players[arg.ID] = player
+	return nil
+}
+
+func (s *Store) GetPlayerInGame(ctx context.Context, arg database.GetPlayerInGameParams) (database.Player, error) {
+	defer s.lock()()
+
+	player, ok := s.data.players[arg.ID]
+	if !ok || player.GameID != arg.GameID {
+		return database.Player{}, pgx.ErrNoRows
+	}
+	return player, nil
+}
+
+func (s *Store) ListPlayersByGameCode(ctx context.Context, code string) ([]database.ListPlayersByGameCodeRow, error) {
+	defer s.lock()()
+
+	game, err := s.gameByCode(code)
+	if err != nil {
+		return nil, nil
+	}
+
+	var items []database.ListPlayersByGameCodeRow
+	for _, p := range s.playersInGame(game.ID) {
+		if p.LeftAt.Valid {
+			continue
+		}
+		items = append(items, database.ListPlayersByGameCodeRow{
+			ID:         p.ID,
+			Nickname:   p.Nickname,
+			IsHost:     p.IsHost,
+			JoinedAt:   p.JoinedAt,
+			Seat:       p.Seat,
+			LastSeenAt: p.LastSeenAt,
+		})
+	}
+	return items, nil
+}
+
+func (s *Store) SetGameHost(ctx context.Context, arg database.SetGameHostParams) error {
+	defer s.lock()()
+
+	for id, p := range s.data.players {
+		if p.GameID == arg.GameID {
+			p.IsHost = pgtype.Bool{Bool: id == arg.ID, Valid: true}
+			s.data.players[id] = p
+		}
+	}
+	return nil
+}
+
+func (s *Store) TouchPlayers(ctx context.Context, arg database.TouchPlayersParams) error {
+	defer s.lock()()
+
+	for _, id := range arg.Ids {
+		if p, ok := s.data.players[id]; ok {
+			p.LastSeenAt = arg.SeenAt
+			s.data.players[id] = p
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListAbsentHosts(ctx context.Context, seenBefore pgtype.Timestamptz) ([]database.ListAbsentHostsRow, error) {
+	defer s.lock()()
+
+	var hosts []database.ListAbsentHostsRow
+	gameIDs := map[int64]int64{}
+	for _, p := range s.data.players {
+		game := s.data.games[p.GameID]
+		if !p.IsHost.Bool || p.LeftAt.Valid || game.Status == "ended" {
+			continue
+		}
+		// 和 SQL 一樣，沒蓋過章的 NULL 不算
+		if !p.LastSeenAt.Valid || !p.LastSeenAt.Time.Before(seenBefore.Time) {
+			continue
+		}
+		hosts = append(hosts, database.ListAbsentHostsRow{Code: game.Code, ID: p.ID})
+		gameIDs[p.ID] = game.ID
+	}
+	slices.SortFunc(hosts, func(a, b database.ListAbsentHostsRow) int {
+		return cmp.Compare(gameIDs[a.ID], gameIDs[b.ID])
+	})
+	return hosts, nil
+}
+
+func (s *Store) UpdatePlayerSeats(ctx context.Context, arg database.UpdatePlayerSeatsParams) error {
+	defer s.lock()()
+
+	seats := map[int64]int32{}
+	for i, id := range arg.Ids {
+		seats[id] = arg.Seats[i]
+	}
+	players := s.playersInGame(arg.GameID)
+	taken := map[int32]bool{}
+	for i, p := range players {
+		if seat, ok := seats[p.ID]; ok {
+			players[i].Seat = seat
+		}
+		// 和資料庫一樣，整句更新完才檢查座位是否重複
+		if taken[players[i].Seat] {
+			return errUniqueViolation("players_game_seat_key")
+		}
+		taken[players[i].Seat] = true
+	}
+	for _, p := range players {
+		s.data.players[p.ID] = p
+	}
+	return nil
+}
+
+// playersInGame returns the game's players, including those who left, in
+// seat order.
+func (s *Store) playersInGame(gameID int64) []database.Player {
+	var players []database.Player
+	for _, p := range s.data.players {
+		if p.GameID == gameID {
+			players = append(players, p)
+		}
+	}
+	slices.SortFunc(players, func(a, b database.Player) int {
+		return cmp.Compare(a.Seat, b.Seat)
+	})
+	return players
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+func (s *Store) GetNextQueuedQuestion(ctx context.Context, arg database.GetNextQueuedQuestionParams) (database.GetNextQueuedQuestionRow, error) {
+	defer s.lock()()
+
+	queue := s.data.queues[arg.GameID]
+	for pos := max(int(arg.Position), 0); pos < len(queue); pos++ {
+		q, ok := s.data.questions[queue[pos]]
+		if ok && !q.DeletedAt.Valid {
+			return database.GetNextQueuedQuestionRow{
+				Position: int32(pos),
+				ID:       q.ID,
+				Content:  q.Content,
+			}, nil
+		}
+	}
+	return database.GetNextQueuedQuestionRow{}, pgx.ErrNoRows
+}
+
+func (s *Store) ClearQuestionQueue(ctx context.Context, gameID int64) error {
+	defer s.lock()()
+
+	delete(s.data.queues, gameID)
+	return nil
+}
+
+func (s *Store) FillQuestionQueue(ctx context.Context, arg database.FillQuestionQueueParams) error {
+	defer s.lock()()
+
+	if _, ok := s.data.games[arg.GameID]; !ok {
+		return errForeignKeyViolation("game_question_queue_game_id_fkey")
+	}
+	for _, id := range arg.QuestionIds {
+		if _, ok := s.data.questions[id]; !ok {
+			return errForeignKeyViolation("game_question_queue_question_id_fkey")
+		}
+	}
+	if len(s.data.queues[arg.GameID]) > 0 {
+		return errUniqueViolation("game_question_queue_pkey")
+	}
+
+	s.data.queues[arg.GameID] = slices.Clone(arg.QuestionIds)
+	return nil
+}
+
+func (s *Store) UpdateGameQuestionCursor(ctx context.Context, arg database.UpdateGameQuestionCursorParams) error {
+	defer s.lock()()
+
+	if game, ok := s.data.games[arg.ID]; ok {
+		game.QuestionCursor = arg.QuestionCursor
+		s.data.games[arg.ID] = game
+	}
+	return nil
+}
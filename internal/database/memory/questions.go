@@ -0,0 +1,234 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+func (s *Store) GetQuestionByID(ctx context.Context, id int64) (string, error) {
+	defer s.lock()()
+
+	question, ok := s.data.questions[id]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+	return question.Content, nil
+}
+
+func (s *Store) ListQuestionIDsByLevel(ctx context.Context, level string) ([]int64, error) {
+	defer s.lock()()
+
+	var ids []int64
+	for _, q := range s.matchQuestions(pgtype.Text{String: level, Valid: true}, pgtype.Text{}) {
+		ids = append(ids, q.ID)
+	}
+	return ids, nil
+}
+
+func (s *Store) CreateQuestion(ctx context.Context, arg database.CreateQuestionParams) (database.Question, error) {
+	defer s.lock()()
+
+	if err := checkQuestion(arg.Level, arg.Content); err != nil {
+		return database.Question{}, err
+	}
+	if s.hasQuestion(arg.Level, arg.Content, 0) {
+		return database.Question{}, errUniqueViolation("questions_global_content")
+	}
+
+	question := database.Question{
+		ID:        s.data.newID(),
+		Level:     arg.Level,
+		Content:   arg.Content,
+		Tags:      tagsOrEmpty(arg.Tags),
+		CreatedAt: s.now(),
+		UpdatedAt: s.now(),
+	}
+	s.data.questions[question.ID] = question
+	return question, nil
+}
+
+func (s *Store) ImportQuestion(ctx context.Context, arg database.ImportQuestionParams) (int64, error) {
+	defer s.lock()()
+
+	if err := checkQuestion(arg.Level, arg.Content); err != nil {
+		return 0, err
+	}
+	if s.hasQuestion(arg.Level, arg.Content, 0) {
+		return 0, nil
+	}
+
+	question := database.Question{
+		ID:        s.data.newID(),
+		Level:     arg.Level,
+		Content:   arg.Content,
+		Tags:      tagsOrEmpty(arg.Tags),
+		CreatedAt: s.now(),
+		UpdatedAt: s.now(),
+	}
+	s.data.questions[question.ID] = question
+	return 1, nil
+}
+
+func (s *Store) GetActiveQuestion(ctx context.Context, id int64) (database.Question, error) {
+	defer s.lock()()
+
+	question, ok := s.data.questions[id]
+	if !ok || !isPublic(question) {
+		return database.Question{}, pgx.ErrNoRows
+	}
+	return question, nil
+}
+
+func (s *Store) UpdateQuestion(ctx context.Context, arg database.UpdateQuestionParams) (database.Question, error) {
+	defer s.lock()()
+
+	question, ok := s.data.questions[arg.ID]
+	if !ok || !isPublic(question) {
+		return database.Question{}, pgx.ErrNoRows
+	}
+	if err := checkQuestion(arg.Level, arg.Content); err != nil {
+		return database.Question{}, err
+	}
+	if s.hasQuestion(arg.Level, arg.Content, arg.ID) {
+		return database.Question{}, errUniqueViolation("questions_global_content")
+	}
+
+	question.Level = arg.Level
+	question.Content = arg.Content
+	question.Tags = tagsOrEmpty(arg.Tags)
+	question.UpdatedAt = arg.UpdatedAt
+	s.data.questions[question.ID] = question
+	return question, nil
+}
+
+func (s *Store) SoftDeleteQuestion(ctx context.Context, arg database.SoftDeleteQuestionParams) (int64, error) {
+	defer s.lock()()
+
+	question, ok := s.data.questions[arg.ID]
+	if !ok || !isPublic(question) {
+		return 0, nil
+	}
+
+	question.DeletedAt = arg.DeletedAt
+	question.UpdatedAt = arg.DeletedAt
+	s.data.questions[arg.ID] = question
+	return 1, nil
+}
+
+func (s *Store) ListQuestions(ctx context.Context, arg database.ListQuestionsParams) ([]database.Question, error) {
+	defer s.lock()()
+
+	matched := s.matchQuestions(arg.Level, arg.Search)
+	start := min(int(arg.Offset), len(matched))
+	end := min(start+int(arg.Limit), len(matched))
+	return matched[start:end], nil
+}
+
+func (s *Store) CountQuestions(ctx context.Context, arg database.CountQuestionsParams) (int64, error) {
+	defer s.lock()()
+	return int64(len(s.matchQuestions(arg.Level, arg.Search))), nil
+}
+
+func (s *Store) ListQuestionContents(ctx context.Context) ([]string, error) {
+	defer s.lock()()
+
+	var contents []string
+	for _, q := range s.matchQuestions(pgtype.Text{}, pgtype.Text{}) {
+		contents = append(contents, q.Content)
+	}
+	return contents, nil
+}
+
+func (s *Store) ExportQuestions(ctx context.Context, level pgtype.Text) ([]database.Question, error) {
+	defer s.lock()()
+	return s.matchQuestions(level, pgtype.Text{}), nil
+}
+
+// matchQuestions applies the ListQuestions filters, ordered by id.
+func (s *Store) matchQuestions(level, search pgtype.Text) []database.Question {
+	var matched []database.Question
+	for _, q := range s.data.questions {
+		if !isPublic(q) {
+			continue
+		}
+		if level.Valid && q.Level != level.String {
+			continue
+		}
+		if search.Valid && !strings.Contains(strings.ToLower(q.Content), strings.ToLower(search.String)) {
+			continue
+		}
+		matched = append(matched, q)
+	}
+	slices.SortFunc(matched, func(a, b database.Question) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return matched
+}
+
+func (s *Store) CreatePrivateQuestion(ctx context.Context, arg database.CreatePrivateQuestionParams) (database.Question, error) {
+	defer s.lock()()
+
+	if err := checkQuestion(arg.Level, arg.Content); err != nil {
+		return database.Question{}, err
+	}
+	if _, ok := s.data.games[arg.GameID.Int64]; arg.GameID.Valid && !ok {
+		return database.Question{}, errForeignKeyViolation("questions_game_id_fkey")
+	}
+
+	question := database.Question{
+		ID:        s.data.newID(),
+		Level:     arg.Level,
+		Content:   arg.Content,
+		Tags:      []string{},
+		GameID:    arg.GameID,
+		CreatedAt: s.now(),
+		UpdatedAt: s.now(),
+	}
+	s.data.questions[question.ID] = question
+	return question, nil
+}
+
+// isPublic reports whether q belongs to the global pool: not deleted and not
+// private to a game.
+func isPublic(q database.Question) bool {
+	return !q.DeletedAt.Valid && !q.GameID.Valid
+}
+
+// hasQuestion mirrors the questions_global_content unique index: another
+// active global question with the same level and content, ignoring case.
+func (s *Store) hasQuestion(level, content string, except int64) bool {
+	for _, q := range s.data.questions {
+		if q.ID != except && isPublic(q) && q.Level == level && strings.EqualFold(q.Content, content) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkQuestion mirrors the level CHECK and VARCHAR(100) on questions.
+func checkQuestion(level, content string) error {
+	switch level {
+	case "easy", "normal", "spicy":
+	default:
+		return errCheckViolation("questions_level_check")
+	}
+	if utf8.RuneCountInString(content) > 100 {
+		return errValueTooLong()
+	}
+	return nil
+}
+
+// tagsOrEmpty mirrors the '{}' default of questions.tags.
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
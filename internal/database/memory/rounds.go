@@ -0,0 +1,223 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+func (s *Store) CreateRound(ctx context.Context, arg database.CreateRoundParams) (database.CreateRoundRow, error) {
+	defer s.lock()()
+
+	if _, ok := s.data.games[arg.GameID]; !ok {
+		return database.CreateRoundRow{}, errForeignKeyViolation("rounds_game_id_fkey")
+	}
+	if _, ok := s.data.questions[arg.QuestionID]; !ok {
+		return database.CreateRoundRow{}, errForeignKeyViolation("rounds_question_id_fkey")
+	}
+	if _, ok := s.data.players[arg.CurrentPlayerID]; !ok {
+		return database.CreateRoundRow{}, errForeignKeyViolation("rounds_current_player_id_fkey")
+	}
+	for _, r := range s.data.rounds {
+		if r.GameID == arg.GameID && inProgress(r.Status) {
+			return database.CreateRoundRow{}, errUniqueViolation("rounds_one_pending_per_game")
+		}
+	}
+
+	round := database.Round{
+		ID:              s.data.newID(),
+		GameID:          arg.GameID,
+		QuestionID:      arg.QuestionID,
+		CurrentPlayerID: arg.CurrentPlayerID,
+		Status:          "pending",
+		CreatedAt:       s.now(),
+		Seed:            arg.Seed,
+		Commitment:      arg.Commitment,
+		DrawInput:       arg.DrawInput,
+		DeadlineAt:      arg.DeadlineAt,
+		Attempt:         1,
+	}
+	round.IsJoker.Valid = true
+	s.data.rounds[round.ID] = round
+
+	return database.CreateRoundRow{
+		ID:              round.ID,
+		QuestionID:      round.QuestionID,
+		CurrentPlayerID: round.CurrentPlayerID,
+		Status:          round.Status,
+		CreatedAt:       round.CreatedAt,
+		Commitment:      round.Commitment,
+		DeadlineAt:      round.DeadlineAt,
+	}, nil
+}
+
+func (s *Store) GetCurrentRoundByGameCode(ctx context.Context, code string) (database.GetCurrentRoundByGameCodeRow, error) {
+	defer s.lock()()
+
+	game, err := s.gameByCode(code)
+	if err != nil {
+		return database.GetCurrentRoundByGameCodeRow{}, err
+	}
+	round, err := s.latestRound(game.ID)
+	if err != nil {
+		return database.GetCurrentRoundByGameCodeRow{}, err
+	}
+
+	return database.GetCurrentRoundByGameCodeRow{
+		ID:              round.ID,
+		CurrentPlayerID: round.CurrentPlayerID,
+		QuestionID:      round.QuestionID,
+		IsJoker:         round.IsJoker,
+		CreatedAt:       round.CreatedAt,
+		GameID:          game.ID,
+		Status:          round.Status,
+		Level:           game.Level,
+		QuestionContent: s.data.questions[round.QuestionID].Content,
+	}, nil
+}
+
+func (s *Store) GetLatestRoundInGame(ctx context.Context, gameID int64) (database.Round, error) {
+	defer s.lock()()
+	return s.latestRound(gameID)
+}
+
+func (s *Store) GetRoundByID(ctx context.Context, id int64) (database.Round, error) {
+	defer s.lock()()
+
+	round, ok := s.data.rounds[id]
+	if !ok {
+		return database.Round{}, pgx.ErrNoRows
+	}
+	return round, nil
+}
+
+func (s *Store) ClearRoundDeadline(ctx context.Context, id int64) error {
+	defer s.lock()()
+
+	if round, ok := s.data.rounds[id]; ok {
+		round.DeadlineAt = pgtype.Timestamptz{}
+		s.data.rounds[id] = round
+	}
+	return nil
+}
+
+func (s *Store) CountRoundsInGame(ctx context.Context, gameID int64) (int64, error) {
+	defer s.lock()()
+	return int64(len(s.roundsInGame(gameID))), nil
+}
+
+func (s *Store) ListRoundDrawers(ctx context.Context, gameID int64) ([]int64, error) {
+	defer s.lock()()
+
+	var items []int64
+	for _, r := range s.roundsInGame(gameID) {
+		items = append(items, r.CurrentPlayerID)
+	}
+	return items, nil
+}
+
+func (s *Store) ListRoundHistory(ctx context.Context, arg database.ListRoundHistoryParams) ([]database.ListRoundHistoryRow, error) {
+	defer s.lock()()
+
+	rounds := s.roundsInGame(arg.GameID)
+	start := min(int(arg.Offset), len(rounds))
+	end := min(start+int(arg.Limit), len(rounds))
+
+	var items []database.ListRoundHistoryRow
+	for _, r := range rounds[start:end] {
+		row := database.ListRoundHistoryRow{
+			ID:              r.ID,
+			CurrentPlayerID: r.CurrentPlayerID,
+			DrawerNickname:  s.data.players[r.CurrentPlayerID].Nickname,
+			Status:          r.Status,
+			IsJoker:         r.IsJoker,
+			Attempt:         r.Attempt,
+			Penalty:         r.Penalty,
+			QuestionContent: s.data.questions[r.QuestionID].Content,
+			CreatedAt:       r.CreatedAt,
+		}
+		if o, ok := s.data.outcomes[r.ID]; ok {
+			row.Outcome = pgtype.Text{String: o.Outcome, Valid: true}
+			row.Points = pgtype.Int4{Int32: o.Points, Valid: true}
+			row.EndedAt = o.CreatedAt
+		}
+		items = append(items, row)
+	}
+	return items, nil
+}
+
+func (s *Store) ListTimedPendingRounds(ctx context.Context) ([]database.ListTimedPendingRoundsRow, error) {
+	defer s.lock()()
+
+	var items []database.ListTimedPendingRoundsRow
+	for _, r := range s.data.rounds {
+		game := s.data.games[r.GameID]
+		if !inProgress(r.Status) || !r.DeadlineAt.Valid || game.Status != "playing" {
+			continue
+		}
+		items = append(items, database.ListTimedPendingRoundsRow{
+			ID:              r.ID,
+			CurrentPlayerID: r.CurrentPlayerID,
+			DeadlineAt:      r.DeadlineAt,
+			GameCode:        game.Code,
+		})
+	}
+	slices.SortFunc(items, func(a, b database.ListTimedPendingRoundsRow) int {
+		return a.DeadlineAt.Time.Compare(b.DeadlineAt.Time)
+	})
+	return items, nil
+}
+
+func (s *Store) UpdateRoundPhase(ctx context.Context, arg database.UpdateRoundPhaseParams) error {
+	defer s.lock()()
+
+	if round, ok := s.data.rounds[arg.ID]; ok {
+		round.Status = arg.Status
+		round.Attempt = arg.Attempt
+		round.Penalty = arg.Penalty
+		s.data.rounds[arg.ID] = round
+	}
+	return nil
+}
+
+func (s *Store) UpdateRoundStatus(ctx context.Context, arg database.UpdateRoundStatusParams) error {
+	defer s.lock()()
+
+	if round, ok := s.data.rounds[arg.ID]; ok {
+		round.IsJoker = arg.IsJoker
+		round.Status = arg.Status
+		s.data.rounds[arg.ID] = round
+	}
+	return nil
+}
+
+// inProgress mirrors the condition of the rounds_one_pending_per_game index.
+func inProgress(status string) bool {
+	return status == "pending" || status == "voting" || status == "answered"
+}
+
+// roundsInGame returns the game's rounds in creation order.
+func (s *Store) roundsInGame(gameID int64) []database.Round {
+	var rounds []database.Round
+	for _, r := range s.data.rounds {
+		if r.GameID == gameID {
+			rounds = append(rounds, r)
+		}
+	}
+	slices.SortFunc(rounds, func(a, b database.Round) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return rounds
+}
+
+func (s *Store) latestRound(gameID int64) (database.Round, error) {
+	rounds := s.roundsInGame(gameID)
+	if len(rounds) == 0 {
+		return database.Round{}, pgx.ErrNoRows
+	}
+	return rounds[len(rounds)-1], nil
+}
@@ -0,0 +1,133 @@
+// Package memory is an in-process implementation of database.Store. It keeps
+// every table in maps guarded by one mutex, so a transaction simply holds
+// the mutex and restores a snapshot on rollback.
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+type tables struct {
+	nextID    int64
+	games     map[int64]database.Game
+	players   map[int64]database.Player
+	questions map[int64]database.Question
+	rounds    map[int64]database.Round
+	decks     map[int64]database.Deck
+	deckItems map[database.DeckQuestion]bool
+	// queues holds each game's question queue in position order. Slices are
+	// replaced, never modified in place, so clone can copy the map shallowly.
+	queues   map[int64][]int64
+	votes    map[voteKey]database.RoundVote
+	outcomes map[int64]database.RoundOutcome
+}
+
+func (t *tables) clone() tables {
+	return tables{
+		nextID:    t.nextID,
+		games:     maps.Clone(t.games),
+		players:   maps.Clone(t.players),
+		questions: maps.Clone(t.questions),
+		rounds:    maps.Clone(t.rounds),
+		decks:     maps.Clone(t.decks),
+		deckItems: maps.Clone(t.deckItems),
+		queues:    maps.Clone(t.queues),
+		votes:     maps.Clone(t.votes),
+		outcomes:  maps.Clone(t.outcomes),
+	}
+}
+
+func (t *tables) newID() int64 {
+	t.nextID++
+	return t.nextID
+}
+
+type Store struct {
+	mu    *sync.Mutex
+	data  *tables
+	clock utils.Clock
+	inTx  bool
+}
+
+var _ database.Store = (*Store)(nil)
+
+// New returns an empty store that stamps rows with clock.
+func New(clock utils.Clock) *Store {
+	return &Store{
+		mu:    &sync.Mutex{},
+		clock: clock,
+		data: &tables{
+			games:     make(map[int64]database.Game),
+			players:   make(map[int64]database.Player),
+			questions: make(map[int64]database.Question),
+			rounds:    make(map[int64]database.Round),
+			decks:     make(map[int64]database.Deck),
+			deckItems: make(map[database.DeckQuestion]bool),
+			queues:    make(map[int64][]int64),
+			votes:     make(map[voteKey]database.RoundVote),
+			outcomes:  make(map[int64]database.RoundOutcome),
+		},
+	}
+}
+
+// lock takes the store mutex unless the call runs inside ExecTx, which
+// already holds it. Use as `defer s.lock()()`.
+func (s *Store) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Store) ExecTx(ctx context.Context, fn func(database.Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	if err := fn(&Store{mu: s.mu, data: s.data, clock: s.clock, inTx: true}); err != nil {
+		*s.data = snapshot
+		return err
+	}
+	return nil
+}
+
+// AddQuestion inserts a question directly, mirroring a seed migration.
+func (s *Store) AddQuestion(level, content string) int64 {
+	defer s.lock()()
+
+	id := s.data.newID()
+	s.data.questions[id] = database.Question{
+		ID:        id,
+		Level:     level,
+		Content:   content,
+		Tags:      []string{},
+		CreatedAt: s.now(),
+		UpdatedAt: s.now(),
+	}
+	return id
+}
+
+func (s *Store) now() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: s.clock.Now(), Valid: true}
+}
+
+func (s *Store) gameByCode(code string) (database.Game, error) {
+	for _, g := range s.data.games {
+		if g.Code == code {
+			return g, nil
+		}
+	}
+	return database.Game{}, pgx.ErrNoRows
+}
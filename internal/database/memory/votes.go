@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+// voteKey is the primary key of round_votes.
+type voteKey struct {
+	roundID int64
+	attempt int32
+	voterID int64
+}
+
+func (s *Store) UpsertRoundVote(ctx context.Context, arg database.UpsertRoundVoteParams) error {
+	defer s.lock()()
+
+	if _, ok := s.data.rounds[arg.RoundID]; !ok {
+		return errForeignKeyViolation("round_votes_round_id_fkey")
+	}
+	if _, ok := s.data.players[arg.VoterID]; !ok {
+		return errForeignKeyViolation("round_votes_voter_id_fkey")
+	}
+
+	key := voteKey{roundID: arg.RoundID, attempt: arg.Attempt, voterID: arg.VoterID}
+	vote, ok := s.data.votes[key]
+	if !ok {
+		vote = database.RoundVote{
+			RoundID:   arg.RoundID,
+			VoterID:   arg.VoterID,
+			Attempt:   arg.Attempt,
+			CreatedAt: s.now(),
+		}
+	}
+	vote.Accept = arg.Accept
+	s.data.votes[key] = vote
+	return nil
+}
+
+func (s *Store) CountRoundVotes(ctx context.Context, arg database.CountRoundVotesParams) (database.CountRoundVotesRow, error) {
+	defer s.lock()()
+
+	var row database.CountRoundVotesRow
+	for key, vote := range s.data.votes {
+		if key.roundID != arg.RoundID || key.attempt != arg.Attempt || s.data.players[key.voterID].LeftAt.Valid {
+			continue
+		}
+		if vote.Accept {
+			row.Accepts++
+		} else {
+			row.Rejects++
+		}
+	}
+	return row, nil
+}
+
+func (s *Store) GetRoundVote(ctx context.Context, arg database.GetRoundVoteParams) (database.RoundVote, error) {
+	defer s.lock()()
+
+	vote, ok := s.data.votes[voteKey{roundID: arg.RoundID, attempt: arg.Attempt, voterID: arg.VoterID}]
+	if !ok {
+		return database.RoundVote{}, pgx.ErrNoRows
+	}
+	return vote, nil
+}
@@ -8,21 +8,61 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Game struct {
+type Deck struct {
 	ID        int64
-	Code      string
-	Level     string
-	Status    string
+	Name      string
+	GameID    pgtype.Int8
 	CreatedAt pgtype.Timestamptz
 	UpdatedAt pgtype.Timestamptz
 }
 
+type DeckQuestion struct {
+	DeckID     int64
+	QuestionID int64
+}
+
+type Game struct {
+	ID                     int64
+	Code                   string
+	Level                  string
+	Status                 string
+	CreatedAt              pgtype.Timestamptz
+	UpdatedAt              pgtype.Timestamptz
+	DeckID                 pgtype.Int8
+	QuestionCursor         int32
+	DrawMode               string
+	JokerProbability       float64
+	PityDraws              int32
+	PileSafeCards          int32
+	PileJokers             int32
+	DrawsSinceJoker        int32
+	PileSafeLeft           int32
+	PileJokersLeft         int32
+	AnswerTimeLimitSeconds int32
+	TimeoutAction          string
+	VoteRule               string
+	PointsSafe             int32
+	PointsJoker            int32
+	PointsSkipped          int32
+	PointsTimedOut         int32
+	TurnOrder              string
+}
+
+type GameQuestionQueue struct {
+	GameID     int64
+	Position   int32
+	QuestionID int64
+}
+
 type Player struct {
-	ID       int64
-	GameID   int64
-	Nickname string
-	IsHost   pgtype.Bool
-	JoinedAt pgtype.Timestamptz
+	ID         int64
+	GameID     int64
+	Nickname   string
+	IsHost     pgtype.Bool
+	JoinedAt   pgtype.Timestamptz
+	LeftAt     pgtype.Timestamptz
+	Seat       int32
+	LastSeenAt pgtype.Timestamptz
 }
 
 type Question struct {
@@ -31,6 +71,9 @@ type Question struct {
 	Content   string
 	CreatedAt pgtype.Timestamptz
 	UpdatedAt pgtype.Timestamptz
+	DeletedAt pgtype.Timestamptz
+	Tags      []string
+	GameID    pgtype.Int8
 }
 
 type Round struct {
@@ -41,4 +84,28 @@ type Round struct {
 	IsJoker         pgtype.Bool
 	Status          string
 	CreatedAt       pgtype.Timestamptz
+	Seed            []byte
+	Commitment      pgtype.Text
+	DrawInput       []byte
+	DeadlineAt      pgtype.Timestamptz
+	Attempt         int32
+	Penalty         bool
+}
+
+type RoundOutcome struct {
+	RoundID   int64
+	GameID    int64
+	PlayerID  int64
+	Outcome   string
+	Exposed   bool
+	Points    int32
+	CreatedAt pgtype.Timestamptz
+}
+
+type RoundVote struct {
+	RoundID   int64
+	VoterID   int64
+	Attempt   int32
+	Accept    bool
+	CreatedAt pgtype.Timestamptz
 }
@@ -12,7 +12,7 @@ import (
 )
 
 const countPlayersInGame = `-- name: CountPlayersInGame :one
-SELECT COUNT(*) FROM players WHERE game_id = $1
+SELECT COUNT(*) FROM players WHERE game_id = $1 AND left_at IS NULL
 `
 
 func (q *Queries) CountPlayersInGame(ctx context.Context, gameID int64) (int64, error) {
@@ -23,9 +23,9 @@ func (q *Queries) CountPlayersInGame(ctx context.Context, gameID int64) (int64,
 }
 
 const createPlayer = `-- name: CreatePlayer :one
-INSERT INTO players(game_id, nickname, is_host)
-VALUES ($1, $2, $3)
-RETURNING id, nickname, is_host, joined_at
+INSERT INTO players(game_id, nickname, is_host, seat)
+VALUES ($1, $2, $3, (SELECT COALESCE(MAX(seat), 0) + 1 FROM players WHERE game_id = $1))
+RETURNING id, nickname, is_host, joined_at, seat
 `
 
 type CreatePlayerParams struct {
@@ -39,6 +39,7 @@ type CreatePlayerRow struct {
 	Nickname string
 	IsHost   pgtype.Bool
 	JoinedAt pgtype.Timestamptz
+	Seat     int32
 }
 
 func (q *Queries) CreatePlayer(ctx context.Context, arg CreatePlayerParams) (CreatePlayerRow, error) {
@@ -49,37 +50,51 @@ func (q *Queries) CreatePlayer(ctx context.Context, arg CreatePlayerParams) (Cre
 		&i.Nickname,
 		&i.IsHost,
 		&i.JoinedAt,
+		&i.Seat,
 	)
 	return i, err
 }
 
-const deletePlayer = `-- name: DeletePlayer :exec
-DELETE FROM players WHERE id = $1 AND game_id = $2
+const getPlayerInGame = `-- name: GetPlayerInGame :one
+SELECT id, game_id, nickname, is_host, joined_at, left_at, seat, last_seen_at FROM players WHERE id = $1 AND game_id = $2
 `
 
-type DeletePlayerParams struct {
+type GetPlayerInGameParams struct {
 	ID     int64
 	GameID int64
 }
 
-func (q *Queries) DeletePlayer(ctx context.Context, arg DeletePlayerParams) error {
-	_, err := q.db.Exec(ctx, deletePlayer, arg.ID, arg.GameID)
-	return err
+func (q *Queries) GetPlayerInGame(ctx context.Context, arg GetPlayerInGameParams) (Player, error) {
+	row := q.db.QueryRow(ctx, getPlayerInGame, arg.ID, arg.GameID)
+	var i Player
+	err := row.Scan(
+		&i.ID,
+		&i.GameID,
+		&i.Nickname,
+		&i.IsHost,
+		&i.JoinedAt,
+		&i.LeftAt,
+		&i.Seat,
+		&i.LastSeenAt,
+	)
+	return i, err
 }
 
 const listPlayersByGameCode = `-- name: ListPlayersByGameCode :many
-SELECT p.id, p.nickname, p.is_host, p.joined_at
+SELECT p.id, p.nickname, p.is_host, p.joined_at, p.seat, p.last_seen_at
 FROM players p
 JOIN games g ON p.game_id = g.id
-WHERE g.code = $1
-ORDER BY p.joined_at
+WHERE g.code = $1 AND p.left_at IS NULL
+ORDER BY p.seat
 `
 
 type ListPlayersByGameCodeRow struct {
-	ID       int64
-	Nickname string
-	IsHost   pgtype.Bool
-	JoinedAt pgtype.Timestamptz
+	ID         int64
+	Nickname   string
+	IsHost     pgtype.Bool
+	JoinedAt   pgtype.Timestamptz
+	Seat       int32
+	LastSeenAt pgtype.Timestamptz
 }
 
 func (q *Queries) ListPlayersByGameCode(ctx context.Context, code string) ([]ListPlayersByGameCodeRow, error) {
@@ -96,6 +111,8 @@ func (q *Queries) ListPlayersByGameCode(ctx context.Context, code string) ([]Lis
 			&i.Nickname,
 			&i.IsHost,
 			&i.JoinedAt,
+			&i.Seat,
+			&i.LastSeenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -106,3 +123,100 @@ func (q *Queries) ListPlayersByGameCode(ctx context.Context, code string) ([]Lis
 	}
 	return items, nil
 }
+
+const markPlayerLeft = `-- name: MarkPlayerLeft :exec
+UPDATE players SET left_at = $3, is_host = FALSE
+WHERE id = $1 AND game_id = $2 AND left_at IS NULL
+`
+
+type MarkPlayerLeftParams struct {
+	ID     int64
+	GameID int64
+	LeftAt pgtype.Timestamptz
+}
+
+func (q *Queries) MarkPlayerLeft(ctx context.Context, arg MarkPlayerLeftParams) error {
+	_, err := q.db.Exec(ctx, markPlayerLeft, arg.ID, arg.GameID, arg.LeftAt)
+	return err
+}
+
+const setGameHost = `-- name: SetGameHost :exec
+UPDATE players SET is_host = (id = $2) WHERE game_id = $1
+`
+
+type SetGameHostParams struct {
+	GameID int64
+	ID     int64
+}
+
+func (q *Queries) SetGameHost(ctx context.Context, arg SetGameHostParams) error {
+	_, err := q.db.Exec(ctx, setGameHost, arg.GameID, arg.ID)
+	return err
+}
+
+const touchPlayers = `-- name: TouchPlayers :exec
+UPDATE players SET last_seen_at = $1
+WHERE id = ANY($2::bigint[])
+`
+
+type TouchPlayersParams struct {
+	SeenAt pgtype.Timestamptz
+	Ids    []int64
+}
+
+func (q *Queries) TouchPlayers(ctx context.Context, arg TouchPlayersParams) error {
+	_, err := q.db.Exec(ctx, touchPlayers, arg.SeenAt, arg.Ids)
+	return err
+}
+
+const listAbsentHosts = `-- name: ListAbsentHosts :many
+SELECT g.code, p.id
+FROM players p
+JOIN games g ON p.game_id = g.id
+WHERE p.is_host AND p.left_at IS NULL AND g.status <> 'ended'
+  AND p.last_seen_at < $1
+ORDER BY g.id
+`
+
+type ListAbsentHostsRow struct {
+	Code string
+	ID   int64
+}
+
+func (q *Queries) ListAbsentHosts(ctx context.Context, seenBefore pgtype.Timestamptz) ([]ListAbsentHostsRow, error) {
+	rows, err := q.db.Query(ctx, listAbsentHosts, seenBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAbsentHostsRow
+	for rows.Next() {
+		var i ListAbsentHostsRow
+		if err := rows.Scan(&i.Code, &i.ID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePlayerSeats = `-- name: UpdatePlayerSeats :exec
+UPDATE players AS p
+SET seat = s.seat
+FROM unnest($1::bigint[], $2::int[]) AS s(id, seat)
+WHERE p.game_id = $3 AND p.id = s.id
+`
+
+type UpdatePlayerSeatsParams struct {
+	Ids    []int64
+	Seats  []int32
+	GameID int64
+}
+
+func (q *Queries) UpdatePlayerSeats(ctx context.Context, arg UpdatePlayerSeatsParams) error {
+	_, err := q.db.Exec(ctx, updatePlayerSeats, arg.Ids, arg.Seats, arg.GameID)
+	return err
+}
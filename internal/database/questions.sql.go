@@ -7,8 +7,163 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countQuestions = `-- name: CountQuestions :one
+SELECT COUNT(*) FROM questions
+WHERE game_id IS NULL AND deleted_at IS NULL
+  AND ($1::text IS NULL OR level = $1)
+  AND ($2::text IS NULL OR content ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+`
+
+type CountQuestionsParams struct {
+	Level  pgtype.Text
+	Search pgtype.Text
+}
+
+func (q *Queries) CountQuestions(ctx context.Context, arg CountQuestionsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countQuestions, arg.Level, arg.Search)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createQuestion = `-- name: CreateQuestion :one
+INSERT INTO questions (level, content, tags)
+VALUES ($1, $2, $3)
+RETURNING id, level, content, created_at, updated_at, deleted_at, tags, game_id
+`
+
+type CreateQuestionParams struct {
+	Level   string
+	Content string
+	Tags    []string
+}
+
+func (q *Queries) CreateQuestion(ctx context.Context, arg CreateQuestionParams) (Question, error) {
+	row := q.db.QueryRow(ctx, createQuestion, arg.Level, arg.Content, arg.Tags)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Level,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Tags,
+		&i.GameID,
+	)
+	return i, err
+}
+
+const importQuestion = `-- name: ImportQuestion :execrows
+INSERT INTO questions (level, content, tags)
+VALUES ($1, $2, $3)
+ON CONFLICT (level, lower(content)) WHERE game_id IS NULL AND deleted_at IS NULL
+DO NOTHING
+`
+
+type ImportQuestionParams struct {
+	Level   string
+	Content string
+	Tags    []string
+}
+
+func (q *Queries) ImportQuestion(ctx context.Context, arg ImportQuestionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, importQuestion, arg.Level, arg.Content, arg.Tags)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createPrivateQuestion = `-- name: CreatePrivateQuestion :one
+INSERT INTO questions (level, content, game_id)
+VALUES ($1, $2, $3)
+RETURNING id, level, content, created_at, updated_at, deleted_at, tags, game_id
+`
+
+type CreatePrivateQuestionParams struct {
+	Level   string
+	Content string
+	GameID  pgtype.Int8
+}
+
+func (q *Queries) CreatePrivateQuestion(ctx context.Context, arg CreatePrivateQuestionParams) (Question, error) {
+	row := q.db.QueryRow(ctx, createPrivateQuestion, arg.Level, arg.Content, arg.GameID)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Level,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Tags,
+		&i.GameID,
+	)
+	return i, err
+}
+
+const exportQuestions = `-- name: ExportQuestions :many
+SELECT id, level, content, created_at, updated_at, deleted_at, tags, game_id FROM questions
+WHERE game_id IS NULL AND deleted_at IS NULL
+  AND ($1::text IS NULL OR level = $1)
+ORDER BY id
+`
+
+func (q *Queries) ExportQuestions(ctx context.Context, level pgtype.Text) ([]Question, error) {
+	rows, err := q.db.Query(ctx, exportQuestions, level)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Question
+	for rows.Next() {
+		var i Question
+		if err := rows.Scan(
+			&i.ID,
+			&i.Level,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Tags,
+			&i.GameID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveQuestion = `-- name: GetActiveQuestion :one
+SELECT id, level, content, created_at, updated_at, deleted_at, tags, game_id FROM questions
+WHERE id = $1 AND game_id IS NULL AND deleted_at IS NULL
+`
+
+func (q *Queries) GetActiveQuestion(ctx context.Context, id int64) (Question, error) {
+	row := q.db.QueryRow(ctx, getActiveQuestion, id)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Level,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Tags,
+		&i.GameID,
+	)
+	return i, err
+}
+
 const getQuestionByID = `-- name: GetQuestionByID :one
 SELECT content FROM questions
 WHERE id = $1
@@ -21,21 +176,159 @@ func (q *Queries) GetQuestionByID(ctx context.Context, id int64) (string, error)
 	return content, err
 }
 
-const getRandomQuestionByLevel = `-- name: GetRandomQuestionByLevel :one
-SELECT id, content FROM questions
-WHERE level = $1
-ORDER BY RANDOM()
-LIMIT 1
+const listQuestionContents = `-- name: ListQuestionContents :many
+SELECT content FROM questions
+WHERE game_id IS NULL AND deleted_at IS NULL
 `
 
-type GetRandomQuestionByLevelRow struct {
-	ID      int64
-	Content string
+func (q *Queries) ListQuestionContents(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listQuestionContents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var content string
+		if err := rows.Scan(&content); err != nil {
+			return nil, err
+		}
+		items = append(items, content)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuestionIDsByLevel = `-- name: ListQuestionIDsByLevel :many
+SELECT id FROM questions
+WHERE level = $1 AND game_id IS NULL AND deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) ListQuestionIDsByLevel(ctx context.Context, level string) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listQuestionIDsByLevel, level)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuestions = `-- name: ListQuestions :many
+SELECT id, level, content, created_at, updated_at, deleted_at, tags, game_id FROM questions
+WHERE game_id IS NULL AND deleted_at IS NULL
+  AND ($1::text IS NULL OR level = $1)
+  AND ($2::text IS NULL OR content ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+ORDER BY id
+LIMIT $4 OFFSET $3
+`
+
+type ListQuestionsParams struct {
+	Level  pgtype.Text
+	Search pgtype.Text
+	Offset int32
+	Limit  int32
+}
+
+func (q *Queries) ListQuestions(ctx context.Context, arg ListQuestionsParams) ([]Question, error) {
+	rows, err := q.db.Query(ctx, listQuestions,
+		arg.Level,
+		arg.Search,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Question
+	for rows.Next() {
+		var i Question
+		if err := rows.Scan(
+			&i.ID,
+			&i.Level,
+			&i.Content,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Tags,
+			&i.GameID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const softDeleteQuestion = `-- name: SoftDeleteQuestion :execrows
+UPDATE questions
+SET deleted_at = $2, updated_at = $2
+WHERE id = $1 AND game_id IS NULL AND deleted_at IS NULL
+`
+
+type SoftDeleteQuestionParams struct {
+	ID        int64
+	DeletedAt pgtype.Timestamptz
+}
+
+func (q *Queries) SoftDeleteQuestion(ctx context.Context, arg SoftDeleteQuestionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteQuestion, arg.ID, arg.DeletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateQuestion = `-- name: UpdateQuestion :one
+UPDATE questions
+SET level = $2, content = $3, tags = $4, updated_at = $5
+WHERE id = $1 AND game_id IS NULL AND deleted_at IS NULL
+RETURNING id, level, content, created_at, updated_at, deleted_at, tags, game_id
+`
+
+type UpdateQuestionParams struct {
+	ID        int64
+	Level     string
+	Content   string
+	Tags      []string
+	UpdatedAt pgtype.Timestamptz
 }
 
-func (q *Queries) GetRandomQuestionByLevel(ctx context.Context, level string) (GetRandomQuestionByLevelRow, error) {
-	row := q.db.QueryRow(ctx, getRandomQuestionByLevel, level)
-	var i GetRandomQuestionByLevelRow
-	err := row.Scan(&i.ID, &i.Content)
+func (q *Queries) UpdateQuestion(ctx context.Context, arg UpdateQuestionParams) (Question, error) {
+	row := q.db.QueryRow(ctx, updateQuestion,
+		arg.ID,
+		arg.Level,
+		arg.Content,
+		arg.Tags,
+		arg.UpdatedAt,
+	)
+	var i Question
+	err := row.Scan(
+		&i.ID,
+		&i.Level,
+		&i.Content,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Tags,
+		&i.GameID,
+	)
 	return i, err
 }
@@ -11,16 +11,40 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const clearRoundDeadline = `-- name: ClearRoundDeadline :exec
+UPDATE rounds SET deadline_at = NULL WHERE id = $1
+`
+
+func (q *Queries) ClearRoundDeadline(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, clearRoundDeadline, id)
+	return err
+}
+
+const countRoundsInGame = `-- name: CountRoundsInGame :one
+SELECT COUNT(*) FROM rounds WHERE game_id = $1
+`
+
+func (q *Queries) CountRoundsInGame(ctx context.Context, gameID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countRoundsInGame, gameID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createRound = `-- name: CreateRound :one
-INSERT INTO rounds (game_id, question_id, current_player_id, status)
-VALUES ($1, $2, $3, 'pending')
-RETURNING id, question_id, current_player_id, status, created_at
+INSERT INTO rounds (game_id, question_id, current_player_id, status, seed, commitment, draw_input, deadline_at)
+VALUES ($1, $2, $3, 'pending', $4, $5, $6, $7)
+RETURNING id, question_id, current_player_id, status, created_at, commitment, deadline_at
 `
 
 type CreateRoundParams struct {
 	GameID          int64
 	QuestionID      int64
 	CurrentPlayerID int64
+	Seed            []byte
+	Commitment      pgtype.Text
+	DrawInput       []byte
+	DeadlineAt      pgtype.Timestamptz
 }
 
 type CreateRoundRow struct {
@@ -29,10 +53,20 @@ type CreateRoundRow struct {
 	CurrentPlayerID int64
 	Status          string
 	CreatedAt       pgtype.Timestamptz
+	Commitment      pgtype.Text
+	DeadlineAt      pgtype.Timestamptz
 }
 
 func (q *Queries) CreateRound(ctx context.Context, arg CreateRoundParams) (CreateRoundRow, error) {
-	row := q.db.QueryRow(ctx, createRound, arg.GameID, arg.QuestionID, arg.CurrentPlayerID)
+	row := q.db.QueryRow(ctx, createRound,
+		arg.GameID,
+		arg.QuestionID,
+		arg.CurrentPlayerID,
+		arg.Seed,
+		arg.Commitment,
+		arg.DrawInput,
+		arg.DeadlineAt,
+	)
 	var i CreateRoundRow
 	err := row.Scan(
 		&i.ID,
@@ -40,6 +74,8 @@ func (q *Queries) CreateRound(ctx context.Context, arg CreateRoundParams) (Creat
 		&i.CurrentPlayerID,
 		&i.Status,
 		&i.CreatedAt,
+		&i.Commitment,
+		&i.DeadlineAt,
 	)
 	return i, err
 }
@@ -86,7 +122,7 @@ func (q *Queries) GetCurrentRoundByGameCode(ctx context.Context, code string) (G
 }
 
 const getLatestRoundInGame = `-- name: GetLatestRoundInGame :one
-SELECT id, game_id, question_id, current_player_id, is_joker, status, created_at FROM rounds
+SELECT id, game_id, question_id, current_player_id, is_joker, status, created_at, seed, commitment, draw_input, deadline_at, attempt, penalty FROM rounds
 WHERE game_id = $1
 ORDER BY id DESC
 LIMIT 1
@@ -103,12 +139,18 @@ func (q *Queries) GetLatestRoundInGame(ctx context.Context, gameID int64) (Round
 		&i.IsJoker,
 		&i.Status,
 		&i.CreatedAt,
+		&i.Seed,
+		&i.Commitment,
+		&i.DrawInput,
+		&i.DeadlineAt,
+		&i.Attempt,
+		&i.Penalty,
 	)
 	return i, err
 }
 
 const getRoundByID = `-- name: GetRoundByID :one
-SELECT id, game_id, question_id, current_player_id, is_joker, status, created_at FROM rounds WHERE id = $1
+SELECT id, game_id, question_id, current_player_id, is_joker, status, created_at, seed, commitment, draw_input, deadline_at, attempt, penalty FROM rounds WHERE id = $1
 `
 
 func (q *Queries) GetRoundByID(ctx context.Context, id int64) (Round, error) {
@@ -122,10 +164,173 @@ func (q *Queries) GetRoundByID(ctx context.Context, id int64) (Round, error) {
 		&i.IsJoker,
 		&i.Status,
 		&i.CreatedAt,
+		&i.Seed,
+		&i.Commitment,
+		&i.DrawInput,
+		&i.DeadlineAt,
+		&i.Attempt,
+		&i.Penalty,
 	)
 	return i, err
 }
 
+const listRoundDrawers = `-- name: ListRoundDrawers :many
+SELECT current_player_id FROM rounds
+WHERE game_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListRoundDrawers(ctx context.Context, gameID int64) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listRoundDrawers, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var current_player_id int64
+		if err := rows.Scan(&current_player_id); err != nil {
+			return nil, err
+		}
+		items = append(items, current_player_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoundHistory = `-- name: ListRoundHistory :many
+SELECT r.id, r.current_player_id, p.nickname AS drawer_nickname,
+       r.status, r.is_joker, r.attempt, r.penalty,
+       q.content AS question_content,
+       o.outcome, o.points,
+       r.created_at, o.created_at AS ended_at
+FROM rounds r
+JOIN players p ON r.current_player_id = p.id
+JOIN questions q ON r.question_id = q.id
+LEFT JOIN round_outcomes o ON o.round_id = r.id
+WHERE r.game_id = $1
+ORDER BY r.id
+LIMIT $3 OFFSET $2
+`
+
+type ListRoundHistoryParams struct {
+	GameID int64
+	Offset int32
+	Limit  int32
+}
+
+type ListRoundHistoryRow struct {
+	ID              int64
+	CurrentPlayerID int64
+	DrawerNickname  string
+	Status          string
+	IsJoker         pgtype.Bool
+	Attempt         int32
+	Penalty         bool
+	QuestionContent string
+	Outcome         pgtype.Text
+	Points          pgtype.Int4
+	CreatedAt       pgtype.Timestamptz
+	EndedAt         pgtype.Timestamptz
+}
+
+func (q *Queries) ListRoundHistory(ctx context.Context, arg ListRoundHistoryParams) ([]ListRoundHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listRoundHistory, arg.GameID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRoundHistoryRow
+	for rows.Next() {
+		var i ListRoundHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CurrentPlayerID,
+			&i.DrawerNickname,
+			&i.Status,
+			&i.IsJoker,
+			&i.Attempt,
+			&i.Penalty,
+			&i.QuestionContent,
+			&i.Outcome,
+			&i.Points,
+			&i.CreatedAt,
+			&i.EndedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTimedPendingRounds = `-- name: ListTimedPendingRounds :many
+SELECT r.id, r.current_player_id, r.deadline_at, g.code AS game_code
+FROM rounds r
+JOIN games g ON r.game_id = g.id
+WHERE r.status IN ('pending', 'voting', 'answered')
+  AND r.deadline_at IS NOT NULL AND g.status = 'playing'
+ORDER BY r.deadline_at
+`
+
+type ListTimedPendingRoundsRow struct {
+	ID              int64
+	CurrentPlayerID int64
+	DeadlineAt      pgtype.Timestamptz
+	GameCode        string
+}
+
+func (q *Queries) ListTimedPendingRounds(ctx context.Context) ([]ListTimedPendingRoundsRow, error) {
+	rows, err := q.db.Query(ctx, listTimedPendingRounds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTimedPendingRoundsRow
+	for rows.Next() {
+		var i ListTimedPendingRoundsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CurrentPlayerID,
+			&i.DeadlineAt,
+			&i.GameCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateRoundPhase = `-- name: UpdateRoundPhase :exec
+UPDATE rounds SET status = $2, attempt = $3, penalty = $4 WHERE id = $1
+`
+
+type UpdateRoundPhaseParams struct {
+	ID      int64
+	Status  string
+	Attempt int32
+	Penalty bool
+}
+
+func (q *Queries) UpdateRoundPhase(ctx context.Context, arg UpdateRoundPhaseParams) error {
+	_, err := q.db.Exec(ctx, updateRoundPhase,
+		arg.ID,
+		arg.Status,
+		arg.Attempt,
+		arg.Penalty,
+	)
+	return err
+}
+
 const updateRoundStatus = `-- name: UpdateRoundStatus :exec
 UPDATE rounds SET is_joker = $2, status = $3 WHERE id = $1
 `
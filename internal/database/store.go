@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Store is the persistence layer used by the handlers. *Queries implements
+// it on top of Postgres and memory.Store implements it in process.
+type Store interface {
+	Querier
+	// ExecTx runs fn against a transaction-bound Store. The transaction is
+	// committed when fn returns nil and rolled back otherwise.
+	ExecTx(ctx context.Context, fn func(Store) error) error
+}
+
+var _ Store = (*Queries)(nil)
+
+var ErrTxNotSupported = errors.New("database: connection cannot begin a transaction")
+
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+func (q *Queries) ExecTx(ctx context.Context, fn func(Store) error) error {
+	db, ok := q.db.(txBeginner)
+	if !ok {
+		return ErrTxNotSupported
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(q.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
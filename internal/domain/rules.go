@@ -0,0 +1,112 @@
+package domain
+
+import "fmt"
+
+type DrawMode string
+
+const (
+	// DrawRandom makes every draw an independent roll against
+	// JokerProbability.
+	DrawRandom DrawMode = "random"
+	// DrawPile deals from a finite shuffled pile of safe cards and jokers,
+	// reshuffled once it runs out.
+	DrawPile DrawMode = "pile"
+)
+
+const maxPileSize = 100
+
+// Rules decide how the card of a round is drawn.
+type Rules struct {
+	Mode             DrawMode `json:"drawMode"`
+	JokerProbability float64  `json:"jokerProbability"`
+	// PityDraws forces a joker once this many draws in a row came up safe.
+	// Zero turns it off. Only used in DrawRandom mode.
+	PityDraws  int `json:"pityDraws"`
+	PileSafe   int `json:"pileSafeCards"`
+	PileJokers int `json:"pileJokers"`
+}
+
+// DefaultRules keeps the original one-in-three odds.
+func DefaultRules() Rules {
+	return Rules{
+		Mode:             DrawRandom,
+		JokerProbability: 1.0 / 3,
+		PileSafe:         2,
+		PileJokers:       1,
+	}
+}
+
+// Validate checks the settings the draw mode uses and rejects the ones it
+// ignores, keyed like the rules object of a request (pileSafeCards, ...).
+func (r Rules) Validate() map[string]string {
+	errs := map[string]string{}
+	switch r.Mode {
+	case DrawRandom:
+		if r.JokerProbability < 0 || r.JokerProbability > 1 {
+			errs["jokerProbability"] = "must be between 0 and 1"
+		}
+		if r.PityDraws < 0 {
+			errs["pityDraws"] = "must not be negative"
+		}
+	case DrawPile:
+		if r.PityDraws != 0 {
+			errs["pityDraws"] = "is not used in pile mode"
+		}
+		if r.PileSafe < 0 || r.PileSafe > maxPileSize {
+			errs["pileSafeCards"] = fmt.Sprintf("must be between 0 and %d", maxPileSize)
+		}
+		if r.PileJokers < 0 || r.PileJokers > maxPileSize {
+			errs["pileJokers"] = fmt.Sprintf("must be between 0 and %d", maxPileSize)
+		}
+		if r.PileSafe+r.PileJokers == 0 {
+			errs["pileSafeCards"] = "pile must hold at least one card"
+		}
+	default:
+		errs["drawMode"] = "must be one of random, pile"
+	}
+	return errs
+}
+
+// DrawState is what a game remembers between draws.
+type DrawState struct {
+	SinceJoker int `json:"drawsSinceJoker"`
+	SafeLeft   int `json:"pileSafeLeft"`
+	JokersLeft int `json:"pileJokersLeft"`
+}
+
+// Source is the randomness Draw needs. *math/rand.Rand satisfies it.
+type Source interface {
+	Float64() float64
+	Intn(n int) int
+}
+
+// Draw decides whether the next card is a joker and returns the state for
+// the following draw.
+func Draw(rules Rules, state DrawState, src Source) (bool, DrawState) {
+	var isJoker bool
+	switch rules.Mode {
+	case DrawPile:
+		if state.SafeLeft+state.JokersLeft <= 0 {
+			state.SafeLeft, state.JokersLeft = rules.PileSafe, rules.PileJokers
+		}
+		// 從剩下的牌裡抽一張，等同於洗好的牌堆逐張翻開
+		isJoker = src.Intn(state.SafeLeft+state.JokersLeft) < state.JokersLeft
+		if isJoker {
+			state.JokersLeft--
+		} else {
+			state.SafeLeft--
+		}
+	default:
+		isJoker = src.Float64() < rules.JokerProbability
+		if rules.PityDraws > 0 && state.SinceJoker+1 >= rules.PityDraws {
+			isJoker = true
+		}
+	}
+
+	if isJoker {
+		state.SinceJoker = 0
+	} else {
+		state.SinceJoker++
+	}
+	return isJoker, state
+}
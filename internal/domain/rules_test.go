@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// fixedSource returns the same roll every time; Intn always picks the first
+// card, which is a joker whenever the pile still holds one.
+type fixedSource struct {
+	roll float64
+}
+
+func (s fixedSource) Float64() float64 { return s.roll }
+func (s fixedSource) Intn(int) int     { return 0 }
+
+func TestDrawRandomProbability(t *testing.T) {
+	tests := []struct {
+		probability float64
+		min, max    int
+	}{
+		{0, 0, 0},
+		{0.25, 2300, 2700},
+		{1.0 / 3, 3100, 3570},
+		{0.5, 4750, 5250},
+		{1, 10000, 10000},
+	}
+	for _, tt := range tests {
+		rules := Rules{Mode: DrawRandom, JokerProbability: tt.probability}
+		src := rand.New(rand.NewSource(1))
+
+		jokers := 0
+		var state DrawState
+		for range 10000 {
+			var isJoker bool
+			isJoker, state = Draw(rules, state, src)
+			if isJoker {
+				jokers++
+				if state.SinceJoker != 0 {
+					t.Fatalf("p=%v: SinceJoker = %d after a joker, want 0", tt.probability, state.SinceJoker)
+				}
+			}
+		}
+		if jokers < tt.min || jokers > tt.max {
+			t.Errorf("p=%v: %d jokers in 10000 draws, want %d..%d", tt.probability, jokers, tt.min, tt.max)
+		}
+	}
+}
+
+func TestDrawPity(t *testing.T) {
+	tests := []struct {
+		name       string
+		pity       int
+		sinceJoker int
+		roll       float64
+		wantJoker  bool
+		wantSince  int
+	}{
+		{"off", 0, 50, 0.99, false, 51},
+		{"below threshold", 4, 2, 0.99, false, 3},
+		{"forced on the nth draw", 4, 3, 0.99, true, 0},
+		{"past threshold", 4, 9, 0.99, true, 0},
+		{"every draw", 1, 0, 0.99, true, 0},
+		{"rolled joker resets", 4, 2, 0, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := Rules{Mode: DrawRandom, JokerProbability: 0.1, PityDraws: tt.pity}
+			isJoker, state := Draw(rules, DrawState{SinceJoker: tt.sinceJoker}, fixedSource{roll: tt.roll})
+			if isJoker != tt.wantJoker || state.SinceJoker != tt.wantSince {
+				t.Fatalf("Draw = %v, SinceJoker %d; want %v, %d", isJoker, state.SinceJoker, tt.wantJoker, tt.wantSince)
+			}
+		})
+	}
+
+	// 從零開始連續抽，第 N 張一定是鬼牌
+	rules := Rules{Mode: DrawRandom, PityDraws: 3}
+	var state DrawState
+	var got []bool
+	for range 6 {
+		var isJoker bool
+		isJoker, state = Draw(rules, state, fixedSource{roll: 0.5})
+		got = append(got, isJoker)
+	}
+	want := []bool{false, false, true, false, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("draws = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestDrawPile(t *testing.T) {
+	rules := Rules{Mode: DrawPile, PileSafe: 3, PileJokers: 2}
+	tests := []struct {
+		name      string
+		state     DrawState
+		src       Source
+		wantJoker bool
+		wantState DrawState
+	}{
+		{"empty pile reshuffles", DrawState{}, fixedSource{}, true, DrawState{SafeLeft: 3, JokersLeft: 1}},
+		{"jokers first", DrawState{SafeLeft: 1, JokersLeft: 1}, fixedSource{}, true, DrawState{SafeLeft: 1}},
+		{"only safe left", DrawState{SafeLeft: 2, SinceJoker: 1}, fixedSource{}, false, DrawState{SafeLeft: 1, SinceJoker: 2}},
+		{"last card", DrawState{SafeLeft: 1}, fixedSource{}, false, DrawState{SinceJoker: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isJoker, state := Draw(rules, tt.state, tt.src)
+			if isJoker != tt.wantJoker || state != tt.wantState {
+				t.Fatalf("Draw = %v, %+v; want %v, %+v", isJoker, state, tt.wantJoker, tt.wantState)
+			}
+		})
+	}
+
+	// 每一輪洗牌剛好發出設定的張數，用完才重洗
+	src := rand.New(rand.NewSource(1))
+	var state DrawState
+	for cycle := range 20 {
+		safe, jokers := 0, 0
+		for range rules.PileSafe + rules.PileJokers {
+			var isJoker bool
+			isJoker, state = Draw(rules, state, src)
+			if isJoker {
+				jokers++
+			} else {
+				safe++
+			}
+		}
+		if safe != rules.PileSafe || jokers != rules.PileJokers {
+			t.Fatalf("cycle %d dealt %d safe and %d jokers, want %d and %d", cycle, safe, jokers, rules.PileSafe, rules.PileJokers)
+		}
+		if state.SafeLeft != 0 || state.JokersLeft != 0 {
+			t.Fatalf("cycle %d left %+v, want an empty pile", cycle, state)
+		}
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		want  []string
+	}{
+		{"default", DefaultRules(), nil},
+		{"random with pity", Rules{Mode: DrawRandom, JokerProbability: 0.2, PityDraws: 5}, nil},
+		{"pile", Rules{Mode: DrawPile, PileSafe: 4, PileJokers: 1}, nil},
+		{"pile of jokers", Rules{Mode: DrawPile, PileJokers: maxPileSize}, nil},
+		{"unknown mode", Rules{Mode: "deck"}, []string{"drawMode"}},
+		{"negative probability", Rules{Mode: DrawRandom, JokerProbability: -0.1}, []string{"jokerProbability"}},
+		{"probability above one", Rules{Mode: DrawRandom, JokerProbability: 1.5}, []string{"jokerProbability"}},
+		{"negative pity", Rules{Mode: DrawRandom, PityDraws: -1}, []string{"pityDraws"}},
+		{"pity in pile mode", Rules{Mode: DrawPile, PityDraws: 3, PileSafe: 2, PileJokers: 1}, []string{"pityDraws"}},
+		{"negative safe cards", Rules{Mode: DrawPile, PileSafe: -1, PileJokers: 2}, []string{"pileSafeCards"}},
+		{"too many safe cards", Rules{Mode: DrawPile, PileSafe: maxPileSize + 1, PileJokers: 1}, []string{"pileSafeCards"}},
+		{"negative jokers", Rules{Mode: DrawPile, PileSafe: 2, PileJokers: -1}, []string{"pileJokers"}},
+		{"too many jokers", Rules{Mode: DrawPile, PileSafe: 2, PileJokers: maxPileSize + 1}, []string{"pileJokers"}},
+		{"empty pile", Rules{Mode: DrawPile}, []string{"pileSafeCards"}},
+		{"several errors", Rules{Mode: DrawPile, PityDraws: 1, PileSafe: -1, PileJokers: -1}, []string{"pityDraws", "pileSafeCards", "pileJokers"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.rules.Validate()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Validate = %v, want keys %v", errs, tt.want)
+			}
+			for _, key := range tt.want {
+				if errs[key] == "" {
+					t.Fatalf("Validate = %v, want an error for %s", errs, key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "fmt"
+
+// Outcome is how a round ended for its drawer.
+type Outcome string
+
+const (
+	OutcomeSafe  Outcome = "safe"
+	OutcomeJoker Outcome = "joker"
+	// OutcomeSkipped is a round the timer skipped without drawing.
+	OutcomeSkipped Outcome = "skipped"
+	// OutcomeTimedOut is a round whose card the timer drew for the drawer,
+	// whatever the card.
+	OutcomeTimedOut Outcome = "timed_out"
+)
+
+const maxPoints = 100
+
+// Scoring gives the points a drawer earns for each outcome. Points may be
+// negative.
+type Scoring struct {
+	Safe     int
+	Joker    int
+	Skipped  int
+	TimedOut int
+}
+
+// DefaultScoring rewards every safe draw with a point.
+func DefaultScoring() Scoring {
+	return Scoring{Safe: 1}
+}
+
+func (s Scoring) Points(outcome Outcome) int {
+	switch outcome {
+	case OutcomeSafe:
+		return s.Safe
+	case OutcomeJoker:
+		return s.Joker
+	case OutcomeSkipped:
+		return s.Skipped
+	case OutcomeTimedOut:
+		return s.TimedOut
+	}
+	return 0
+}
+
+// Validate keeps every award within ±maxPoints. Errors are keyed safe,
+// joker, skipped and timedOut, the fields of the request's scoring object.
+func (s Scoring) Validate() map[string]string {
+	errs := map[string]string{}
+	for field, points := range map[string]int{
+		"safe":     s.Safe,
+		"joker":    s.Joker,
+		"skipped":  s.Skipped,
+		"timedOut": s.TimedOut,
+	} {
+		if points < -maxPoints || points > maxPoints {
+			errs[field] = fmt.Sprintf("must be between %d and %d", -maxPoints, maxPoints)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type GameStatus string
+
+const (
+	GameWaiting GameStatus = "waiting"
+	GamePlaying GameStatus = "playing"
+	GameEnded   GameStatus = "ended"
+)
+
+type RoundStatus string
+
+const (
+	RoundPending RoundStatus = "pending"
+	// RoundVoting waits for the other players to accept or reject the
+	// drawer's answer.
+	RoundVoting RoundStatus = "voting"
+	// RoundAnswered has an accepted (or penalised) answer and waits for the
+	// draw.
+	RoundAnswered RoundStatus = "answered"
+	RoundRevealed RoundStatus = "revealed"
+	RoundDone     RoundStatus = "done"
+	// RoundSkipped ends a round whose drawer ran out of time without a card.
+	RoundSkipped RoundStatus = "skipped"
+)
+
+var (
+	ErrIllegalTransition = errors.New("illegal state transition")
+	ErrGameNotStarted    = errors.New("game has not started")
+	ErrGameEnded         = errors.New("game has ended")
+	ErrRoundInProgress   = errors.New("current round is still in progress")
+	ErrVotingOff         = errors.New("voting is off in this game")
+	ErrAnswerNotAccepted = errors.New("the answer has not been accepted yet")
+	ErrPickRequired      = errors.New("the next player has to be picked")
+	ErrPickNotAllowed    = errors.New("the next player cannot be picked now")
+)
+
+// TransitionError reports a status change that the state machine forbids.
+// It matches ErrIllegalTransition with errors.Is.
+type TransitionError struct {
+	Entity string
+	From   string
+	To     string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s cannot go from %q to %q", e.Entity, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrIllegalTransition
+}
+
+var gameTransitions = map[GameStatus][]GameStatus{
+	GameWaiting: {GamePlaying, GameEnded},
+	GamePlaying: {GameEnded},
+}
+
+// Voting rounds may still be drawn or skipped directly when their timer
+// runs out.
+var roundTransitions = map[RoundStatus][]RoundStatus{
+	RoundPending:  {RoundVoting, RoundRevealed, RoundDone, RoundSkipped},
+	RoundVoting:   {RoundPending, RoundAnswered, RoundRevealed, RoundDone, RoundSkipped},
+	RoundAnswered: {RoundRevealed, RoundDone, RoundSkipped},
+}
+
+func (s GameStatus) CanTransitionTo(next GameStatus) bool {
+	return slices.Contains(gameTransitions[s], next)
+}
+
+// TransitionTo returns a *TransitionError when the game may not move to next.
+func (s GameStatus) TransitionTo(next GameStatus) error {
+	if !s.CanTransitionTo(next) {
+		return &TransitionError{Entity: "game", From: string(s), To: string(next)}
+	}
+	return nil
+}
+
+func (s RoundStatus) CanTransitionTo(next RoundStatus) bool {
+	return slices.Contains(roundTransitions[s], next)
+}
+
+// TransitionTo returns a *TransitionError when the round may not move to next.
+func (s RoundStatus) TransitionTo(next RoundStatus) error {
+	if !s.CanTransitionTo(next) {
+		return &TransitionError{Entity: "round", From: string(s), To: string(next)}
+	}
+	return nil
+}
+
+// IsFinal reports whether the round is over, drawn or skipped.
+func (s RoundStatus) IsFinal() bool {
+	return len(roundTransitions[s]) == 0
+}
+
+// IsDrawn reports whether the round's card has been drawn.
+func (s RoundStatus) IsDrawn() bool {
+	return s == RoundRevealed || s == RoundDone
+}
+
+// CanJoin checks that new players may still enter the game.
+func CanJoin(game GameStatus) error {
+	if game == GameEnded {
+		return ErrGameEnded
+	}
+	return nil
+}
+
+// CanPlay checks that rounds of the game may still be played.
+func CanPlay(game GameStatus) error {
+	switch game {
+	case GamePlaying:
+		return nil
+	case GameEnded:
+		return ErrGameEnded
+	default:
+		return ErrGameNotStarted
+	}
+}
+
+// CanStartNextRound checks that a follow-up round may be created. latest is
+// nil when the game has no rounds yet.
+func CanStartNextRound(game GameStatus, latest *RoundStatus) error {
+	if err := CanPlay(game); err != nil {
+		return err
+	}
+	if latest != nil && !latest.IsFinal() {
+		return ErrRoundInProgress
+	}
+	return nil
+}
+
+// CanDraw checks that the drawer may draw the card of a round. When the
+// game votes on answers the answer must have gone through the vote first.
+func CanDraw(round RoundStatus, rule VoteRule) error {
+	if rule.Enabled() && (round == RoundPending || round == RoundVoting) {
+		return ErrAnswerNotAccepted
+	}
+	return round.TransitionTo(RoundDone)
+}
@@ -0,0 +1,190 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+var (
+	allGameStatuses  = []GameStatus{GameWaiting, GamePlaying, GameEnded}
+	allRoundStatuses = []RoundStatus{RoundPending, RoundVoting, RoundAnswered, RoundRevealed, RoundDone, RoundSkipped}
+)
+
+func TestGameTransitions(t *testing.T) {
+	allowed := map[[2]GameStatus]bool{
+		{GameWaiting, GamePlaying}: true,
+		{GameWaiting, GameEnded}:   true,
+		{GamePlaying, GameEnded}:   true,
+	}
+
+	for _, from := range allGameStatuses {
+		for _, to := range allGameStatuses {
+			want := allowed[[2]GameStatus{from, to}]
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				if got := from.CanTransitionTo(to); got != want {
+					t.Fatalf("CanTransitionTo = %v, want %v", got, want)
+				}
+				err := from.TransitionTo(to)
+				if want {
+					if err != nil {
+						t.Fatalf("TransitionTo = %v, want nil", err)
+					}
+					return
+				}
+				if !errors.Is(err, ErrIllegalTransition) {
+					t.Fatalf("TransitionTo = %v, want ErrIllegalTransition", err)
+				}
+				var te *TransitionError
+				if !errors.As(err, &te) || te.Entity != "game" || te.From != string(from) || te.To != string(to) {
+					t.Fatalf("TransitionTo = %#v, want a game TransitionError", err)
+				}
+			})
+		}
+	}
+}
+
+func TestRoundTransitions(t *testing.T) {
+	allowed := map[[2]RoundStatus]bool{
+		{RoundPending, RoundVoting}:    true,
+		{RoundPending, RoundRevealed}:  true,
+		{RoundPending, RoundDone}:      true,
+		{RoundPending, RoundSkipped}:   true,
+		{RoundVoting, RoundPending}:    true,
+		{RoundVoting, RoundAnswered}:   true,
+		{RoundVoting, RoundRevealed}:   true,
+		{RoundVoting, RoundDone}:       true,
+		{RoundVoting, RoundSkipped}:    true,
+		{RoundAnswered, RoundRevealed}: true,
+		{RoundAnswered, RoundDone}:     true,
+		{RoundAnswered, RoundSkipped}:  true,
+	}
+
+	for _, from := range allRoundStatuses {
+		for _, to := range allRoundStatuses {
+			want := allowed[[2]RoundStatus{from, to}]
+			t.Run(string(from)+"->"+string(to), func(t *testing.T) {
+				if got := from.CanTransitionTo(to); got != want {
+					t.Fatalf("CanTransitionTo = %v, want %v", got, want)
+				}
+				err := from.TransitionTo(to)
+				if want {
+					if err != nil {
+						t.Fatalf("TransitionTo = %v, want nil", err)
+					}
+					return
+				}
+				var te *TransitionError
+				if !errors.As(err, &te) || !errors.Is(err, ErrIllegalTransition) || te.Entity != "round" {
+					t.Fatalf("TransitionTo = %#v, want a round TransitionError", err)
+				}
+			})
+		}
+	}
+}
+
+func TestRoundStatusPredicates(t *testing.T) {
+	tests := []struct {
+		status RoundStatus
+		final  bool
+		drawn  bool
+	}{
+		{RoundPending, false, false},
+		{RoundVoting, false, false},
+		{RoundAnswered, false, false},
+		{RoundRevealed, true, true},
+		{RoundDone, true, true},
+		{RoundSkipped, true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsFinal(); got != tt.final {
+			t.Errorf("%s.IsFinal() = %v, want %v", tt.status, got, tt.final)
+		}
+		if got := tt.status.IsDrawn(); got != tt.drawn {
+			t.Errorf("%s.IsDrawn() = %v, want %v", tt.status, got, tt.drawn)
+		}
+	}
+}
+
+func TestCanPlay(t *testing.T) {
+	tests := []struct {
+		game GameStatus
+		want error
+	}{
+		{GameWaiting, ErrGameNotStarted},
+		{GamePlaying, nil},
+		{GameEnded, ErrGameEnded},
+	}
+	for _, tt := range tests {
+		if err := CanPlay(tt.game); !errors.Is(err, tt.want) {
+			t.Errorf("CanPlay(%s) = %v, want %v", tt.game, err, tt.want)
+		}
+	}
+}
+
+func TestCanJoin(t *testing.T) {
+	for _, game := range allGameStatuses {
+		err := CanJoin(game)
+		if game == GameEnded {
+			if !errors.Is(err, ErrGameEnded) {
+				t.Errorf("CanJoin(%s) = %v, want ErrGameEnded", game, err)
+			}
+		} else if err != nil {
+			t.Errorf("CanJoin(%s) = %v, want nil", game, err)
+		}
+	}
+}
+
+func TestCanStartNextRound(t *testing.T) {
+	status := func(s RoundStatus) *RoundStatus { return &s }
+	tests := []struct {
+		game   GameStatus
+		latest *RoundStatus
+		want   error
+	}{
+		{GameWaiting, nil, ErrGameNotStarted},
+		{GameEnded, nil, ErrGameEnded},
+		{GameEnded, status(RoundDone), ErrGameEnded},
+		{GamePlaying, nil, nil},
+		{GamePlaying, status(RoundPending), ErrRoundInProgress},
+		{GamePlaying, status(RoundVoting), ErrRoundInProgress},
+		{GamePlaying, status(RoundAnswered), ErrRoundInProgress},
+		{GamePlaying, status(RoundRevealed), nil},
+		{GamePlaying, status(RoundDone), nil},
+		{GamePlaying, status(RoundSkipped), nil},
+	}
+	for _, tt := range tests {
+		err := CanStartNextRound(tt.game, tt.latest)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("CanStartNextRound(%s, %v) = %v, want %v", tt.game, tt.latest, err, tt.want)
+		}
+	}
+}
+
+func TestCanDraw(t *testing.T) {
+	tests := []struct {
+		round RoundStatus
+		rule  VoteRule
+		want  error
+	}{
+		{RoundPending, VoteOff, nil},
+		{RoundVoting, VoteOff, nil},
+		{RoundAnswered, VoteOff, nil},
+		{RoundRevealed, VoteOff, ErrIllegalTransition},
+		{RoundDone, VoteOff, ErrIllegalTransition},
+		{RoundSkipped, VoteOff, ErrIllegalTransition},
+		{RoundPending, VotePenalty, ErrAnswerNotAccepted},
+		{RoundVoting, VotePenalty, ErrAnswerNotAccepted},
+		{RoundAnswered, VotePenalty, nil},
+		{RoundDone, VotePenalty, ErrIllegalTransition},
+		{RoundPending, VoteReanswer, ErrAnswerNotAccepted},
+		{RoundVoting, VoteReanswer, ErrAnswerNotAccepted},
+		{RoundAnswered, VoteReanswer, nil},
+		{RoundSkipped, VoteReanswer, ErrIllegalTransition},
+	}
+	for _, tt := range tests {
+		err := CanDraw(tt.round, tt.rule)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("CanDraw(%s, %s) = %v, want %v", tt.round, tt.rule, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+type TimeoutAction string
+
+const (
+	// TimeoutAutoDraw draws the card for a drawer who ran out of time.
+	TimeoutAutoDraw TimeoutAction = "auto_draw"
+	// TimeoutSkip skips the round and hands the turn to the next player.
+	TimeoutSkip TimeoutAction = "skip"
+)
+
+const MaxAnswerTimeLimit = time.Hour
+
+// Timer limits how long the drawer of a round has to draw. A zero Limit
+// turns it off.
+type Timer struct {
+	Limit  time.Duration
+	Action TimeoutAction
+}
+
+func DefaultTimer() Timer {
+	return Timer{Action: TimeoutAutoDraw}
+}
+
+// Enabled reports whether rounds get a deadline.
+func (t Timer) Enabled() bool {
+	return t.Limit > 0
+}
+
+// Validate bounds the time limit and checks the timeout action. Timer has no
+// JSON form of its own; errors use the request's answerTimeLimitSeconds and
+// timeoutAction names.
+func (t Timer) Validate() map[string]string {
+	errs := map[string]string{}
+	if t.Limit < 0 || t.Limit > MaxAnswerTimeLimit {
+		errs["answerTimeLimitSeconds"] = fmt.Sprintf("must be between 0 and %d", int(MaxAnswerTimeLimit.Seconds()))
+	}
+	switch t.Action {
+	case TimeoutAutoDraw, TimeoutSkip:
+	default:
+		errs["timeoutAction"] = "must be one of auto_draw, skip"
+	}
+	return errs
+}
@@ -0,0 +1,85 @@
+package domain
+
+// TurnOrder decides who draws after the current round.
+type TurnOrder string
+
+const (
+	// TurnSequential goes around the table by seat.
+	TurnSequential TurnOrder = "sequential"
+	// TurnRandom picks at random, giving everybody one turn per cycle.
+	TurnRandom TurnOrder = "random"
+	// TurnLoserPicks lets whoever drew the joker pick the next player. Other
+	// rounds hand the turn on by seat.
+	TurnLoserPicks TurnOrder = "loser_picks"
+	// TurnHostAssigned has the host pick every next player.
+	TurnHostAssigned TurnOrder = "host_assigned"
+)
+
+func (o TurnOrder) Valid() bool {
+	switch o {
+	case TurnSequential, TurnRandom, TurnLoserPicks, TurnHostAssigned:
+		return true
+	}
+	return false
+}
+
+// Seat is a player's fixed place in the turn order.
+type Seat struct {
+	PlayerID int64
+	Number   int32
+}
+
+// NextSeat returns the seat after last in turn order, wrapping around to the
+// lowest seat. seats holds the players still in the game, in seat order;
+// last may belong to a player who has since left. ok is false when seats is
+// empty.
+func NextSeat(seats []Seat, last int32) (Seat, bool) {
+	if len(seats) == 0 {
+		return Seat{}, false
+	}
+	for _, s := range seats {
+		if s.Number > last {
+			return s, true
+		}
+	}
+	return seats[0], true
+}
+
+// OpenSeats returns the seats that have not had a turn in the current cycle.
+// drawers lists the drawer of every round so far, oldest first; a cycle ends
+// once everybody in seats has drawn. At the start of a cycle the last drawer
+// is left out, so nobody draws twice in a row unless they play alone.
+func OpenSeats(seats []Seat, drawers []int64) []Seat {
+	seated := make(map[int64]bool, len(seats))
+	for _, s := range seats {
+		seated[s.PlayerID] = true
+	}
+
+	played := map[int64]bool{}
+	for _, id := range drawers {
+		if !seated[id] {
+			continue
+		}
+		played[id] = true
+		if len(played) == len(seats) {
+			clear(played)
+		}
+	}
+
+	var open []Seat
+	for _, s := range seats {
+		if !played[s.PlayerID] {
+			open = append(open, s)
+		}
+	}
+	if len(played) == 0 && len(open) > 1 && len(drawers) > 0 {
+		last := drawers[len(drawers)-1]
+		for i, s := range open {
+			if s.PlayerID == last {
+				open = append(open[:i], open[i+1:]...)
+				break
+			}
+		}
+	}
+	return open
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNextSeat(t *testing.T) {
+	seats := []Seat{{PlayerID: 10, Number: 1}, {PlayerID: 20, Number: 2}, {PlayerID: 40, Number: 4}}
+	tests := []struct {
+		name string
+		last int32
+		want int64
+	}{
+		{"first round", 0, 10},
+		{"next seat", 1, 20},
+		{"skips the departed seat 3", 2, 40},
+		{"departed last drawer", 3, 40},
+		{"wraps around", 4, 10},
+		{"departed past the end", 9, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NextSeat(seats, tt.last)
+			if !ok || got.PlayerID != tt.want {
+				t.Fatalf("NextSeat(%d) = %+v, %v; want player %d", tt.last, got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := NextSeat(nil, 1); ok {
+		t.Fatal("NextSeat with no seats: ok = true")
+	}
+}
+
+func TestOpenSeats(t *testing.T) {
+	seats := []Seat{{PlayerID: 1, Number: 1}, {PlayerID: 2, Number: 2}, {PlayerID: 3, Number: 3}}
+	tests := []struct {
+		name    string
+		seats   []Seat
+		drawers []int64
+		want    []int64
+	}{
+		{"first round", seats, nil, []int64{1, 2, 3}},
+		{"mid cycle", seats, []int64{2}, []int64{1, 3}},
+		{"last of the cycle", seats, []int64{2, 3}, []int64{1}},
+		{"cycle exhausted leaves out the last drawer", seats, []int64{2, 3, 1}, []int64{2, 3}},
+		{"second cycle", seats, []int64{2, 3, 1, 3}, []int64{1, 2}},
+		{"second cycle exhausted", seats, []int64{2, 3, 1, 3, 2, 1}, []int64{2, 3}},
+		{"departed drawers do not count", seats, []int64{9, 2, 8}, []int64{1, 3}},
+		{"cycle shrinks when a player leaves", seats[:2], []int64{3, 1}, []int64{2}},
+		{"alone", seats[:1], []int64{1, 1}, []int64{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			for _, s := range OpenSeats(tt.seats, tt.drawers) {
+				got = append(got, s.PlayerID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("OpenSeats = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTurnOrderValid(t *testing.T) {
+	for _, o := range []TurnOrder{TurnSequential, TurnRandom, TurnLoserPicks, TurnHostAssigned} {
+		if !o.Valid() {
+			t.Errorf("%q.Valid() = false", o)
+		}
+	}
+	if TurnOrder("clockwise").Valid() {
+		t.Error(`"clockwise".Valid() = true`)
+	}
+}
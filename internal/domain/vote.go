@@ -0,0 +1,56 @@
+package domain
+
+// VoteRule decides whether the other players vote on the drawer's answer,
+// and what a rejected answer costs.
+type VoteRule string
+
+const (
+	VoteOff VoteRule = "off"
+	// VotePenalty makes the drawer turn over an extra card.
+	VotePenalty VoteRule = "penalty"
+	// VoteReanswer sends the drawer back to answer again.
+	VoteReanswer VoteRule = "reanswer"
+)
+
+func (r VoteRule) Valid() bool {
+	switch r {
+	case VoteOff, VotePenalty, VoteReanswer:
+		return true
+	}
+	return false
+}
+
+func (r VoteRule) Enabled() bool {
+	return r == VotePenalty || r == VoteReanswer
+}
+
+type VoteResult string
+
+const (
+	VoteUndecided VoteResult = ""
+	VoteAccepted  VoteResult = "accepted"
+	VoteRejected  VoteResult = "rejected"
+)
+
+// Tally counts the votes on one answer. Eligible is every player but the
+// drawer.
+type Tally struct {
+	Accepts  int `json:"accepts"`
+	Rejects  int `json:"rejects"`
+	Eligible int `json:"eligible"`
+}
+
+// Result decides the vote once a side holds a strict majority of the
+// eligible players, or once everybody voted. A tie accepts the answer, and
+// so does a game with nobody to vote.
+func (t Tally) Result() VoteResult {
+	switch {
+	case t.Accepts*2 > t.Eligible:
+		return VoteAccepted
+	case t.Rejects*2 > t.Eligible:
+		return VoteRejected
+	case t.Accepts+t.Rejects >= t.Eligible:
+		return VoteAccepted
+	}
+	return VoteUndecided
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "testing"
+
+func TestTallyResult(t *testing.T) {
+	tests := []struct {
+		name  string
+		tally Tally
+		want  VoteResult
+	}{
+		{"nobody to vote", Tally{Eligible: 0}, VoteAccepted},
+		{"no votes yet", Tally{Eligible: 3}, VoteUndecided},
+		{"one of three accepts", Tally{Accepts: 1, Eligible: 3}, VoteUndecided},
+		{"majority accepts", Tally{Accepts: 2, Eligible: 3}, VoteAccepted},
+		{"majority rejects", Tally{Rejects: 2, Eligible: 3}, VoteRejected},
+		{"majority before everyone voted", Tally{Rejects: 3, Accepts: 1, Eligible: 5}, VoteRejected},
+		{"half is not a majority", Tally{Rejects: 2, Eligible: 4}, VoteUndecided},
+		{"tie once everyone voted", Tally{Accepts: 2, Rejects: 2, Eligible: 4}, VoteAccepted},
+		{"unanimous accept", Tally{Accepts: 4, Eligible: 4}, VoteAccepted},
+		{"unanimous reject", Tally{Rejects: 4, Eligible: 4}, VoteRejected},
+		{"single voter rejects", Tally{Rejects: 1, Eligible: 1}, VoteRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tally.Result(); got != tt.want {
+				t.Fatalf("Result = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoteRule(t *testing.T) {
+	tests := []struct {
+		rule    VoteRule
+		valid   bool
+		enabled bool
+	}{
+		{VoteOff, true, false},
+		{VotePenalty, true, true},
+		{VoteReanswer, true, true},
+		{"majority", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Valid(); got != tt.valid {
+			t.Errorf("%q.Valid() = %v, want %v", tt.rule, got, tt.valid)
+		}
+		if got := tt.rule.Enabled(); got != tt.enabled {
+			t.Errorf("%q.Enabled() = %v, want %v", tt.rule, got, tt.enabled)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package fairness implements commit-reveal for joker draws. When a round
+// starts the server picks a random seed and publishes Commit(seed). The seed
+// is revealed with the outcome, so anyone can check it matches the
+// commitment and recompute the draw with Outcome.
+package fairness
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"math"
+
+	"github.com/y3933y3933/joker/internal/domain"
+)
+
+const SeedSize = 32
+
+// NewSeed reads a fresh seed from r, normally crypto/rand.Reader.
+func NewSeed(r io.Reader) ([]byte, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := io.ReadFull(r, seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+// Commit returns the hex SHA-256 of the seed.
+func Commit(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches reports whether seed is the one behind commitment.
+func Matches(seed []byte, commitment string) bool {
+	return subtle.ConstantTimeCompare([]byte(Commit(seed)), []byte(commitment)) == 1
+}
+
+// Input is everything a draw depends on besides the seed. It is fixed when
+// the round starts and published along with the commitment.
+type Input struct {
+	Rules domain.Rules     `json:"rules"`
+	State domain.DrawState `json:"state"`
+}
+
+// Outcome recomputes a draw from its seed and input. A penalty draw turns
+// over a second card from the same source when the first one is safe.
+func Outcome(seed []byte, in Input, penalty bool) (bool, domain.DrawState) {
+	src := NewSource(seed)
+	isJoker, state := domain.Draw(in.Rules, in.State, src)
+	if penalty && !isJoker {
+		isJoker, state = domain.Draw(in.Rules, state, src)
+	}
+	return isJoker, state
+}
+
+// Source is a deterministic domain.Source. Its n-th 64-bit value (n from 0)
+// is the first 8 bytes, big-endian, of HMAC-SHA256(seed, n as 8 big-endian
+// bytes).
+type Source struct {
+	seed []byte
+	n    uint64
+}
+
+func NewSource(seed []byte) *Source {
+	return &Source{seed: seed}
+}
+
+func (s *Source) next() uint64 {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], s.n)
+	s.n++
+
+	mac := hmac.New(sha256.New, s.seed)
+	mac.Write(msg[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+// Float64 returns a value in [0, 1) built from the top 53 bits.
+func (s *Source) Float64() float64 {
+	return float64(s.next()>>11) / (1 << 53)
+}
+
+// Intn returns a value in [0, n), rejecting values that would bias the
+// modulo. It panics if n <= 0.
+func (s *Source) Intn(n int) int {
+	if n <= 0 {
+		panic("fairness: invalid argument to Intn")
+	}
+	bound := uint64(n)
+	limit := math.MaxUint64 - math.MaxUint64%bound
+	for {
+		if v := s.next(); v < limit {
+			return int(v % bound)
+		}
+	}
+}
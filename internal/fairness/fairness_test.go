@@ -0,0 +1,196 @@
+package fairness
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/y3933y3933/joker/internal/domain"
+)
+
+// testSeed is the seed of the fixed vectors below: bytes 0x00 to 0x1f. The
+// expected values were computed independently of this package.
+var testSeed = []byte{
+	0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+	0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+}
+
+func TestNewSeed(t *testing.T) {
+	seed, err := NewSeed(bytes.NewReader(testSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(seed, testSeed) {
+		t.Fatalf("got %x, want %x", seed, testSeed)
+	}
+
+	if _, err := NewSeed(bytes.NewReader(testSeed[:SeedSize-1])); err == nil {
+		t.Fatal("short reader: want an error")
+	}
+}
+
+func TestCommitMatches(t *testing.T) {
+	const want = "630dcd2966c4336691125448bbb25b4ff412a49c732db2c8abc1b8581bd710dd"
+	if got := Commit(testSeed); got != want {
+		t.Fatalf("Commit = %s, want %s", got, want)
+	}
+	if !Matches(testSeed, want) {
+		t.Fatal("seed does not match its own commitment")
+	}
+
+	other, err := NewSeed(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		seed       []byte
+		commitment string
+	}{
+		"other seed":      {other, want},
+		"truncated seed":  {testSeed[:SeedSize-1], want},
+		"upper case hex":  {testSeed, "630DCD2966C4336691125448BBB25B4FF412A49C732DB2C8ABC1B8581BD710DD"},
+		"empty":           {testSeed, ""},
+		"commitment tail": {testSeed, want[:len(want)-2]},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if Matches(tt.seed, tt.commitment) {
+				t.Fatal("Matches = true, want false")
+			}
+		})
+	}
+}
+
+func TestSourceVectors(t *testing.T) {
+	// HMAC-SHA256(seed, n) 的前 8 bytes
+	want := []uint64{0x9f0cd9b94097fe49, 0xc432e059c378eef7, 0xf92ad613cd014c74, 0x96cee9f29e43c395}
+
+	src := NewSource(testSeed)
+	for n, w := range want {
+		if got := src.next(); got != w {
+			t.Fatalf("value %d = %#x, want %#x", n, got, w)
+		}
+	}
+
+	floats := []float64{0.6212898328090829, 0.766401311793263, 0.9733098791448401, 0.5890947549180643}
+	src = NewSource(testSeed)
+	for n, w := range floats {
+		if got := src.Float64(); got != w {
+			t.Fatalf("Float64 %d = %v, want %v", n, got, w)
+		}
+	}
+
+	ints := []int{1, 3, 0, 1}
+	src = NewSource(testSeed)
+	for n, w := range ints {
+		if got := src.Intn(4); got != w {
+			t.Fatalf("Intn(4) %d = %d, want %d", n, got, w)
+		}
+	}
+}
+
+func TestSourceDeterministic(t *testing.T) {
+	seed, err := NewSeed(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := NewSource(seed), NewSource(seed)
+	for n := range 100 {
+		if x, y := a.Intn(1000), b.Intn(1000); x != y {
+			t.Fatalf("draw %d: sources with the same seed gave %d and %d", n, x, y)
+		}
+	}
+
+	// 換一個 seed 就是不同的序列
+	other := append([]byte{}, seed...)
+	other[0] ^= 1
+	if NewSource(seed).next() == NewSource(other).next() {
+		t.Fatal("different seeds gave the same first value")
+	}
+}
+
+func TestSourceIntnRange(t *testing.T) {
+	src := NewSource(testSeed)
+	for _, n := range []int{1, 2, 3, 7, 100} {
+		for range 200 {
+			if got := src.Intn(n); got < 0 || got >= n {
+				t.Fatalf("Intn(%d) = %d", n, got)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Intn(0) did not panic")
+		}
+	}()
+	src.Intn(0)
+}
+
+func TestOutcomeVectors(t *testing.T) {
+	random := func(p float64, pity int) domain.Rules {
+		return domain.Rules{Mode: domain.DrawRandom, JokerProbability: p, PityDraws: pity}
+	}
+	pile := func(safe, jokers int) domain.Rules {
+		return domain.Rules{Mode: domain.DrawPile, PileSafe: safe, PileJokers: jokers}
+	}
+
+	// 第一個 Float64 是 0.6213、第二個 0.7664；第一個 Intn(4) 是 1
+	tests := map[string]struct {
+		in        Input
+		penalty   bool
+		wantJoker bool
+		wantState domain.DrawState
+	}{
+		"random joker": {
+			in:        Input{Rules: random(0.7, 0)},
+			wantJoker: true,
+			wantState: domain.DrawState{SinceJoker: 0},
+		},
+		"random safe": {
+			in:        Input{Rules: random(0.5, 0), State: domain.DrawState{SinceJoker: 3}},
+			wantJoker: false,
+			wantState: domain.DrawState{SinceJoker: 4},
+		},
+		"penalty draws twice": {
+			in:        Input{Rules: random(0.5, 0)},
+			penalty:   true,
+			wantJoker: false,
+			wantState: domain.DrawState{SinceJoker: 2},
+		},
+		"penalty stops at a joker": {
+			in:        Input{Rules: random(0.7, 0), State: domain.DrawState{SinceJoker: 1}},
+			penalty:   true,
+			wantJoker: true,
+			wantState: domain.DrawState{SinceJoker: 0},
+		},
+		"penalty second card forced by pity": {
+			in:        Input{Rules: random(0.5, 2)},
+			penalty:   true,
+			wantJoker: true,
+			wantState: domain.DrawState{SinceJoker: 0},
+		},
+		"pile reshuffled then safe": {
+			in:        Input{Rules: pile(3, 1)},
+			wantJoker: false,
+			wantState: domain.DrawState{SinceJoker: 1, SafeLeft: 2, JokersLeft: 1},
+		},
+		"pile joker": {
+			in:        Input{Rules: pile(3, 1), State: domain.DrawState{SafeLeft: 2, JokersLeft: 2}},
+			wantJoker: true,
+			wantState: domain.DrawState{SafeLeft: 2, JokersLeft: 1},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			isJoker, state := Outcome(testSeed, tt.in, tt.penalty)
+			if isJoker != tt.wantJoker || state != tt.wantState {
+				t.Fatalf("Outcome = %v, %+v; want %v, %+v", isJoker, state, tt.wantJoker, tt.wantState)
+			}
+			// 同樣的 seed 與輸入永遠得到同樣的結果
+			if again, _ := Outcome(testSeed, tt.in, tt.penalty); again != isJoker {
+				t.Fatal("Outcome is not deterministic")
+			}
+		})
+	}
+}
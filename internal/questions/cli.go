@@ -0,0 +1,141 @@
+package questions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+const usage = `usage:
+  questions import [-db-url URL] [-format csv|json] [-dry-run] FILE
+  questions export [-db-url URL] [-format csv|json] [-level LEVEL] [-o FILE]
+
+FILE may be "-" for stdin. The format defaults to the file extension.`
+
+// RunCLI runs the "questions" subcommand with the arguments that follow it.
+func RunCLI(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "import":
+		return runImport(ctx, args[1:], stdin, stdout)
+	case "export":
+		return runExport(ctx, args[1:], stdout)
+	}
+	return errors.New(usage)
+}
+
+func runImport(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("questions import", flag.ContinueOnError)
+	dbURL := fs.String("db-url", os.Getenv("DB_URL"), "DATABASE URL")
+	formatFlag := fs.String("format", "", "File format (csv|json)")
+	dryRun := fs.Bool("dry-run", false, "Validate and report without writing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New(usage)
+	}
+	path := fs.Arg(0)
+
+	format, err := pickFormat(*formatFlag, path)
+	if err != nil {
+		return err
+	}
+
+	in := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	rows, err := Decode(in, format)
+	if err != nil {
+		return err
+	}
+
+	pool, err := pgxpool.New(ctx, *dbURL)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	report, importErr := Import(ctx, database.New(pool), rows, *dryRun)
+	if importErr != nil && !errors.Is(importErr, ErrInvalidRows) {
+		return importErr
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	return importErr
+}
+
+func runExport(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("questions export", flag.ContinueOnError)
+	dbURL := fs.String("db-url", os.Getenv("DB_URL"), "DATABASE URL")
+	formatFlag := fs.String("format", "", "File format (csv|json)")
+	level := fs.String("level", "", "Only export this level")
+	output := fs.String("o", "-", "Output file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *level != "" && !IsLevel(*level) {
+		return fmt.Errorf("invalid level %q", *level)
+	}
+
+	format, err := pickFormat(*formatFlag, *output)
+	if err != nil {
+		return err
+	}
+
+	pool, err := pgxpool.New(ctx, *dbURL)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	rows, err := Export(ctx, database.New(pool), *level)
+	if err != nil {
+		return err
+	}
+
+	if *output == "-" {
+		return Encode(stdout, format, rows)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := Encode(f, format, rows); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// pickFormat prefers the -format flag and falls back to the file extension.
+func pickFormat(flagValue, path string) (Format, error) {
+	if flagValue != "" {
+		return ParseFormat(flagValue)
+	}
+	if path == "-" {
+		return "", errors.New("-format is required when reading stdin or writing stdout")
+	}
+	return FormatFromPath(path)
+}
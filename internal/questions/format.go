@@ -0,0 +1,133 @@
+package questions
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatJSON Format = "json"
+)
+
+// tagSeparator joins tags inside the single CSV tags column.
+const tagSeparator = "|"
+
+var ErrUnknownFormat = errors.New("unknown format, expected csv or json")
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// FormatFromPath guesses the format from a file extension.
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Decode reads rows from a JSON array or a CSV file with a
+// level,content,tags header. Only file-level problems are errors here;
+// row contents are checked by Validate.
+func Decode(r io.Reader, format Format) ([]Row, error) {
+	switch format {
+	case FormatJSON:
+		var rows []Row
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		return rows, nil
+	case FormatCSV:
+		return decodeCSV(r)
+	}
+	return nil, ErrUnknownFormat
+}
+
+func decodeCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("invalid csv: missing header")
+		}
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"level", "content"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("invalid csv: missing %q column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	rows := []Row{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+
+		row := Row{
+			Level:   field(record, "level"),
+			Content: field(record, "content"),
+		}
+		if tags := field(record, "tags"); tags != "" {
+			row.Tags = strings.Split(tags, tagSeparator)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Encode writes rows in the same shape Decode reads, so an export can be
+// imported again unchanged.
+func Encode(w io.Writer, format Format, rows []Row) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"level", "content", "tags"})
+		for _, row := range rows {
+			writer.Write([]string{row.Level, row.Content, strings.Join(row.Tags, tagSeparator)})
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+	return ErrUnknownFormat
+}
@@ -0,0 +1,121 @@
+package questions
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/y3933y3933/joker/internal/database"
+)
+
+// ErrInvalidRows means at least one row failed validation; nothing was
+// written and the report lists every problem.
+var ErrInvalidRows = errors.New("import has invalid rows")
+
+// RowError reports the invalid fields of one row. Row counts data rows
+// from 1, not counting the CSV header.
+type RowError struct {
+	Row    int               `json:"row"`
+	Errors map[string]string `json:"errors"`
+}
+
+type Report struct {
+	DryRun  bool `json:"dryRun"`
+	Total   int  `json:"total"`
+	Created int  `json:"created"`
+	// Duplicates lists rows skipped because the question already exists or
+	// appears earlier in the same file.
+	Duplicates []int      `json:"duplicates"`
+	Errors     []RowError `json:"errors"`
+}
+
+// Import validates every row and, unless dryRun is set or a row is invalid,
+// inserts the new questions in a single transaction.
+func Import(ctx context.Context, store database.Store, rows []Row, dryRun bool) (Report, error) {
+	report := Report{
+		DryRun:     dryRun,
+		Total:      len(rows),
+		Duplicates: []int{},
+		Errors:     []RowError{},
+	}
+
+	err := store.ExecTx(ctx, func(q database.Store) error {
+		existing, err := q.ListQuestionContents(ctx)
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool, len(existing))
+		for _, content := range existing {
+			seen[Normalize(content)] = true
+		}
+
+		var pending []Row
+		var lines []int
+		for i, row := range rows {
+			row = Clean(row)
+			if errs := Validate(row); len(errs) > 0 {
+				report.Errors = append(report.Errors, RowError{Row: i + 1, Errors: errs})
+				continue
+			}
+
+			key := Normalize(row.Content)
+			if seen[key] {
+				report.Duplicates = append(report.Duplicates, i+1)
+				continue
+			}
+			seen[key] = true
+			pending = append(pending, row)
+			lines = append(lines, i+1)
+		}
+
+		if len(report.Errors) > 0 {
+			return ErrInvalidRows
+		}
+		if dryRun {
+			report.Created = len(pending)
+			return nil
+		}
+
+		// 同時進行的匯入可能已經先插入同一題，衝突的就當重複略過
+		for i, row := range pending {
+			created, err := q.ImportQuestion(ctx, database.ImportQuestionParams{
+				Level:   row.Level,
+				Content: row.Content,
+				Tags:    row.Tags,
+			})
+			if err != nil {
+				return err
+			}
+			if created == 0 {
+				report.Duplicates = append(report.Duplicates, lines[i])
+				continue
+			}
+			report.Created++
+		}
+		slices.Sort(report.Duplicates)
+		return nil
+	})
+	if err != nil {
+		report.Created = 0
+	}
+	return report, err
+}
+
+// Export returns the active questions, optionally limited to one level.
+func Export(ctx context.Context, q database.Querier, level string) ([]Row, error) {
+	questions, err := q.ExportQuestions(ctx, pgtype.Text{String: level, Valid: level != ""})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(questions))
+	for _, question := range questions {
+		rows = append(rows, Row{
+			Level:   question.Level,
+			Content: question.Content,
+			Tags:    question.Tags,
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,72 @@
+package questions
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/y3933y3933/joker/internal/database"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/utils"
+)
+
+// staleContents hides the existing questions from Import, as if another
+// import committed them after this one read the list.
+type staleContents struct {
+	database.Store
+}
+
+func (s staleContents) ExecTx(ctx context.Context, fn func(database.Store) error) error {
+	return s.Store.ExecTx(ctx, func(q database.Store) error {
+		return fn(staleContents{q})
+	})
+}
+
+func (staleContents) ListQuestionContents(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func TestImportSkipsDuplicates(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New(utils.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	if _, err := store.CreateQuestion(ctx, database.CreateQuestionParams{Level: "easy", Content: "Existing question"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := []Row{
+		{Level: "easy", Content: "new question"},
+		{Level: "easy", Content: "existing  QUESTION"},
+		{Level: "spicy", Content: "another one"},
+		{Level: "spicy", Content: "Another one"},
+	}
+	report, err := Import(ctx, store, rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 2 || !slices.Equal(report.Duplicates, []int{2, 4}) {
+		t.Fatalf("report %+v, want rows 1 and 3 created", report)
+	}
+
+	// 另一個匯入搶先寫入時，衝突的列也算重複，不會再插一次
+	rows = []Row{
+		{Level: "easy", Content: "NEW QUESTION"},
+		{Level: "normal", Content: "fresh question"},
+		{Level: "spicy", Content: "another one"},
+	}
+	report, err = Import(ctx, staleContents{store}, rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 1 || !slices.Equal(report.Duplicates, []int{1, 3}) {
+		t.Fatalf("report %+v, want only row 2 created", report)
+	}
+
+	total, err := store.CountQuestions(ctx, database.CountQuestionsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 {
+		t.Fatalf("got %d questions, want 4", total)
+	}
+}
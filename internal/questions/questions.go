@@ -0,0 +1,74 @@
+// Package questions holds the question bank rules shared by the admin API
+// and the CLI: validation, deduplication, import and export.
+package questions
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxContentLength matches questions.content VARCHAR(100).
+const MaxContentLength = 100
+
+// Levels matches the CHECK constraint on questions.level.
+var Levels = []string{"easy", "normal", "spicy"}
+
+// Row is one question as it appears in an import or export file.
+type Row struct {
+	Level   string   `json:"level"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+}
+
+func IsLevel(level string) bool {
+	return slices.Contains(Levels, level)
+}
+
+// Clean trims the content and tags and drops empty or repeated tags.
+func Clean(row Row) Row {
+	row.Level = strings.TrimSpace(row.Level)
+	row.Content = strings.TrimSpace(row.Content)
+
+	tags := []string{}
+	for _, tag := range row.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	row.Tags = tags
+	return row
+}
+
+// Validate checks a cleaned row against the questions schema and returns
+// one message per invalid field.
+func Validate(row Row) map[string]string {
+	errs := map[string]string{}
+	switch {
+	case row.Level == "":
+		errs["level"] = "is required"
+	case !IsLevel(row.Level):
+		errs["level"] = "must be one of easy, normal, spicy"
+	}
+	switch {
+	case row.Content == "":
+		errs["content"] = "is required"
+	case utf8.RuneCountInString(row.Content) > MaxContentLength:
+		errs["content"] = "must be at most 100 characters"
+	}
+	// CSV 匯出用 | 串接標籤，標籤本身不能含 |
+	for _, tag := range row.Tags {
+		if strings.Contains(tag, tagSeparator) {
+			errs["tags"] = "must not contain " + tagSeparator
+			break
+		}
+	}
+	return errs
+}
+
+// Normalize is the key used to detect duplicate questions: case and
+// whitespace differences do not make a new question.
+func Normalize(content string) string {
+	return strings.ToLower(strings.Join(strings.Fields(content), " "))
+}
@@ -0,0 +1,35 @@
+package questions
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestValidateRejectsTagSeparator(t *testing.T) {
+	row := Clean(Row{Level: "easy", Content: "q", Tags: []string{"ok", "a|b"}})
+	if errs := Validate(row); errs["tags"] == "" {
+		t.Fatalf("Validate(%v) = %v, want a tags error", row, errs)
+	}
+}
+
+func TestCSVRoundTripsTags(t *testing.T) {
+	rows := []Row{{Level: "easy", Content: "q, with comma", Tags: []string{"party", "ice breaker"}}}
+	for _, row := range rows {
+		if errs := Validate(Clean(row)); len(errs) != 0 {
+			t.Fatalf("Validate(%v) = %v", row, errs)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, FormatCSV, rows); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(&buf, FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !slices.Equal(got[0].Tags, rows[0].Tags) || got[0].Content != rows[0].Content {
+		t.Fatalf("round trip = %+v, want %+v", got, rows)
+	}
+}
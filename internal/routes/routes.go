@@ -2,12 +2,13 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/y3933y3933/joker/internal/api"
 	"github.com/y3933y3933/joker/internal/app"
-	"github.com/y3933y3933/joker/internal/ws"
 )
 
 func SetRoutes(app *app.Application) *gin.Engine {
 	router := gin.Default()
+	authz := app.Authorizer
 
 	router.GET("/api/healthz", app.HealthCheck)
 
@@ -15,21 +16,51 @@ func SetRoutes(app *app.Application) *gin.Engine {
 	games := router.Group("/api/games")
 	{
 		games.POST("/", app.GamesHandler.CreateGame)
-		games.GET("/:code/players", app.PlayersHandler.ListPlayers)
-		games.GET("/:code/rounds/current", app.RoundsHandler.GetCurrentRound)
 		games.POST("/:code/join", app.PlayersHandler.JoinGame)
-		games.POST("/:code/rounds", app.RoundsHandler.CreateRound)
-		games.POST("/:code/rounds/:id/draw", app.RoundsHandler.DrawCard)
-		games.POST("/:code/rounds/next", app.RoundsHandler.CreateNextRound)
-		games.POST("/:code/end", app.RoundsHandler.EndGame)
-		games.DELETE("/:code/players/:player_id", app.RoundsHandler.RemovePlayer)
+		// 公開驗證抽牌結果，不需要 session
+		games.GET("/:code/rounds/:id/verify", app.RoundsHandler.VerifyRound)
 
+		// 以下路由需要 JoinGame 發出的 session token
+		session := games.Group("", api.Authenticate(app.Tokens))
+		session.GET("/:code/state", authz.Require(api.RoleMember), app.StateHandler.GetState)
+		session.GET("/:code/players", authz.Require(api.RoleMember), app.PlayersHandler.ListPlayers)
+		session.GET("/:code/history", authz.Require(api.RoleMember), app.StateHandler.GetHistory)
+		session.GET("/:code/scoreboard", authz.Require(api.RoleMember), app.RoundsHandler.GetScoreboard)
+		session.GET("/:code/rounds/current", authz.Require(api.RoleMember), app.RoundsHandler.GetCurrentRound)
+		session.POST("/:code/rounds", authz.Require(api.RoleHost), app.RoundsHandler.CreateRound)
+		session.POST("/:code/rounds/:id/draw", authz.Require(api.RoleDrawer), app.RoundsHandler.DrawCard)
+		session.POST("/:code/rounds/:id/answer", authz.Require(api.RoleDrawer), app.RoundsHandler.AnswerRound)
+		session.POST("/:code/rounds/:id/votes", authz.Require(api.RoleMember), app.RoundsHandler.Vote)
+		// 主持人，或依輪流方式輪到挑人的玩家（由 handler 檢查）
+		session.POST("/:code/rounds/next", authz.Require(api.RoleMember), app.RoundsHandler.CreateNextRound)
+		session.POST("/:code/end", authz.Require(api.RoleHost), app.RoundsHandler.EndGame)
+		session.PUT("/:code/seats", authz.Require(api.RoleHost), app.PlayersHandler.SetSeats)
+		session.POST("/:code/host/transfer", authz.Require(api.RoleHost), app.RoundsHandler.TransferHost)
+		// 主持人可踢人，玩家也可以自己離開
+		session.DELETE("/:code/players/:player_id", authz.Require(api.RoleHost, api.RoleSelf), app.RoundsHandler.RemovePlayer)
+
+	}
+
+	// admin：題庫與牌組管理
+	admin := router.Group("/api/admin", api.RequireAdmin(app.Config.AdminToken))
+	{
+		admin.GET("/questions", app.QuestionsHandler.ListQuestions)
+		admin.POST("/questions", app.QuestionsHandler.CreateQuestion)
+		admin.POST("/questions/import", app.QuestionsHandler.ImportQuestions)
+		admin.GET("/questions/export", app.QuestionsHandler.ExportQuestions)
+		admin.GET("/questions/:id", app.QuestionsHandler.GetQuestion)
+		admin.PUT("/questions/:id", app.QuestionsHandler.UpdateQuestion)
+		admin.DELETE("/questions/:id", app.QuestionsHandler.DeleteQuestion)
+
+		admin.GET("/decks", app.DecksHandler.ListDecks)
+		admin.POST("/decks", app.DecksHandler.CreateDeck)
+		admin.GET("/decks/:id", app.DecksHandler.GetDeck)
+		admin.POST("/decks/:id/questions", app.DecksHandler.AddQuestions)
+		admin.DELETE("/decks/:id/questions/:question_id", app.DecksHandler.RemoveQuestion)
 	}
 
 	// ws
-	router.GET("/ws/games/:code", func(c *gin.Context) {
-		ws.ServeWS(app.WSHub, c)
-	})
+	router.GET("/ws/games/:code", api.AuthenticateSocket(app.Tokens), authz.Require(api.RoleMember), app.WSHandler.ServeWS)
 
 	return router
 }
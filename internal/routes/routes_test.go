@@ -0,0 +1,234 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/y3933y3933/joker/internal/api"
+	"github.com/y3933y3933/joker/internal/app"
+	"github.com/y3933y3933/joker/internal/auth"
+	"github.com/y3933y3933/joker/internal/database/memory"
+	"github.com/y3933y3933/joker/internal/utils"
+	"github.com/y3933y3933/joker/internal/ws"
+)
+
+// testApplication wires the application the way NewApplication does, on the
+// in-memory store and without flags.
+func testApplication(t *testing.T) *app.Application {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	clock := utils.NewFakeClock(time.Unix(1_700_000_000, 0))
+	store := memory.New(clock)
+	for _, content := range []string{"routes a", "routes b", "routes c"} {
+		store.AddQuestion("easy", content)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	hub := ws.NewHub(ws.DefaultConfig(), ws.NewLocalBackplane())
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+
+	tokens := auth.NewTokenManager([]byte("routes test secret"), time.Hour, clock)
+	roundsHandler := api.NewRoundsHandler(store, logger, hub, utils.NewSeededRandom(1), clock)
+	stateHandler := api.NewStateHandler(store, logger, hub, clock)
+	authorizer := api.NewAuthorizer(store, logger)
+
+	application := &app.Application{
+		Logger:           logger,
+		Store:            store,
+		GamesHandler:     api.NewGamesHandler(store, logger, utils.NewSeededRandom(2), clock),
+		PlayersHandler:   api.NewPlayersHandler(store, logger, hub, tokens),
+		RoundsHandler:    roundsHandler,
+		StateHandler:     stateHandler,
+		QuestionsHandler: api.NewQuestionsHandler(store, logger, clock),
+		DecksHandler:     api.NewDecksHandler(store, logger),
+		WSHub:            hub,
+		WSHandler: &ws.Handler{
+			Hub:     hub,
+			Session: api.SessionFrom,
+			Snapshot: func(ctx context.Context, gameCode string, playerID int64) (any, error) {
+				return stateHandler.Snapshot(ctx, gameCode, playerID)
+			},
+			Commands: api.NewCommandHandler(roundsHandler, authorizer, logger).Handle,
+		},
+		Tokens:     tokens,
+		Authorizer: authorizer,
+	}
+	application.Config.AdminToken = "routes admin token"
+	return application
+}
+
+// call sends a JSON request through the router and decodes the data of a
+// successful response into out, which may be nil.
+func call(t *testing.T, router http.Handler, method, path, token string, body any, want int, out any) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != want {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, rec.Code, want, rec.Body)
+	}
+	if rec.Code >= http.StatusBadRequest {
+		var resp struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Error == "" {
+			t.Fatalf("%s %s: want an error body, got %s", method, path, rec.Body)
+		}
+		return
+	}
+	if out == nil {
+		return
+	}
+	resp := struct {
+		Message string `json:"message"`
+		Data    any    `json:"data"`
+	}{Data: out}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decode %s: %v", method, path, rec.Body, err)
+	}
+	if resp.Message != "success" {
+		t.Fatalf("%s %s: message %q, want success", method, path, resp.Message)
+	}
+}
+
+func TestGameFlow(t *testing.T) {
+	router := SetRoutes(testApplication(t))
+
+	var game api.CreateGameResponse
+	call(t, router, http.MethodPost, "/api/games/", "", gin.H{"level": "easy"}, http.StatusOK, &game)
+	if game.Code == "" || game.Level != "easy" {
+		t.Fatalf("created game %+v", game)
+	}
+	base := "/api/games/" + game.Code
+
+	var host, guest api.JoinGameResponse
+	call(t, router, http.MethodPost, base+"/join", "", gin.H{"nickname": "host"}, http.StatusOK, &host)
+	call(t, router, http.MethodPost, base+"/join", "", gin.H{"nickname": "guest"}, http.StatusOK, &guest)
+	if host.Token == "" || guest.Token == "" {
+		t.Fatal("join returned no session token")
+	}
+	if !host.IsHost || guest.IsHost {
+		t.Fatalf("host %+v, guest %+v: want the first player to host", host, guest)
+	}
+
+	// 沒有或是壞掉的 token 都是 401
+	call(t, router, http.MethodGet, base+"/players", "", nil, http.StatusUnauthorized, nil)
+	call(t, router, http.MethodGet, base+"/players", "not a token", nil, http.StatusUnauthorized, nil)
+	call(t, router, http.MethodPost, base+"/rounds", "", gin.H{"playerId": guest.ID}, http.StatusUnauthorized, nil)
+
+	var players []api.PlayerResponse
+	call(t, router, http.MethodGet, base+"/players", guest.Token, nil, http.StatusOK, &players)
+	if len(players) != 2 {
+		t.Fatalf("got %d players, want 2", len(players))
+	}
+
+	// 別的遊戲的 token 不能進這局
+	var other api.CreateGameResponse
+	var outsider api.JoinGameResponse
+	call(t, router, http.MethodPost, "/api/games/", "", gin.H{"level": "easy"}, http.StatusOK, &other)
+	call(t, router, http.MethodPost, "/api/games/"+other.Code+"/join", "", gin.H{"nickname": "outsider"}, http.StatusOK, &outsider)
+	call(t, router, http.MethodGet, base+"/players", outsider.Token, nil, http.StatusForbidden, nil)
+
+	// 只有主持人能開回合
+	call(t, router, http.MethodPost, base+"/rounds", guest.Token, gin.H{"playerId": guest.ID}, http.StatusForbidden, nil)
+	var round api.CreateRoundResponse
+	call(t, router, http.MethodPost, base+"/rounds", host.Token, gin.H{"playerId": guest.ID}, http.StatusOK, &round)
+	if round.PlayerID != guest.ID || round.Commitment == "" {
+		t.Fatalf("created round %+v", round)
+	}
+	roundPath := fmt.Sprintf("%s/rounds/%d", base, round.RoundID)
+
+	var current api.CurrentRoundResponse
+	call(t, router, http.MethodGet, base+"/rounds/current", host.Token, nil, http.StatusOK, &current)
+	if current.RoundID != round.RoundID || current.CurrentPlayerID != guest.ID {
+		t.Fatalf("current round %+v, want round %d for player %d", current, round.RoundID, guest.ID)
+	}
+
+	// 只有輪到的人能抽，而且只能抽一次
+	call(t, router, http.MethodPost, roundPath+"/draw", host.Token, nil, http.StatusForbidden, nil)
+	call(t, router, http.MethodPost, roundPath+"/draw", guest.Token, nil, http.StatusOK, nil)
+	call(t, router, http.MethodPost, roundPath+"/draw", guest.Token, nil, http.StatusConflict, nil)
+
+	var verified api.VerifyRoundResponse
+	call(t, router, http.MethodGet, roundPath+"/verify", "", nil, http.StatusOK, &verified)
+	if verified.Verified == nil || !*verified.Verified || verified.IsJoker == nil {
+		t.Fatalf("verify %+v: want a verified, drawn round", verified)
+	}
+
+	// 只有主持人能結束遊戲，結束後不能再開回合
+	call(t, router, http.MethodPost, base+"/end", guest.Token, nil, http.StatusForbidden, nil)
+	var standings api.Standings
+	call(t, router, http.MethodPost, base+"/end", host.Token, nil, http.StatusOK, &standings)
+	if len(standings.Entries) != 2 || standings.Winner == nil {
+		t.Fatalf("final standings %+v", standings)
+	}
+	call(t, router, http.MethodPost, base+"/rounds", host.Token, gin.H{"playerId": guest.ID}, http.StatusConflict, nil)
+}
+
+func TestAdminRoutes(t *testing.T) {
+	application := testApplication(t)
+	router := SetRoutes(application)
+
+	call(t, router, http.MethodGet, "/api/admin/questions", "", nil, http.StatusUnauthorized, nil)
+	call(t, router, http.MethodGet, "/api/admin/questions", "wrong", nil, http.StatusUnauthorized, nil)
+	call(t, router, http.MethodGet, "/api/admin/questions", application.Config.AdminToken, nil, http.StatusOK, nil)
+
+	// 玩家的 session token 不能拿來管理題庫
+	var game api.CreateGameResponse
+	var player api.JoinGameResponse
+	call(t, router, http.MethodPost, "/api/games/", "", gin.H{"level": "easy"}, http.StatusOK, &game)
+	call(t, router, http.MethodPost, "/api/games/"+game.Code+"/join", "", gin.H{"nickname": "player"}, http.StatusOK, &player)
+	call(t, router, http.MethodGet, "/api/admin/questions", player.Token, nil, http.StatusUnauthorized, nil)
+}
+
+func TestSessionTokenQuery(t *testing.T) {
+	router := SetRoutes(testApplication(t))
+
+	var game api.CreateGameResponse
+	var player api.JoinGameResponse
+	call(t, router, http.MethodPost, "/api/games/", "", gin.H{"level": "easy"}, http.StatusOK, &game)
+	call(t, router, http.MethodPost, "/api/games/"+game.Code+"/join", "", gin.H{"nickname": "player"}, http.StatusOK, &player)
+
+	// REST 只收 Authorization header，token 不能出現在網址裡
+	call(t, router, http.MethodGet, "/api/games/"+game.Code+"/players?token="+player.Token, "", nil, http.StatusUnauthorized, nil)
+
+	// 瀏覽器的 WebSocket 不能帶 header，只有這條路由收 ?token=
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/games/" + game.Code
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("dial without a token: %v, want 401", err)
+	}
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token="+player.Token, nil)
+	if err != nil {
+		t.Fatalf("dial with ?token=: %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. Pass SystemClock in production and a
+// FakeClock in tests. Handlers pass its time to every update that stamps a
+// row (left_at, updated_at, deleted_at, deadlines). Rows inserted into
+// Postgres still take created_at and joined_at from the column defaults,
+// i.e. the database's clock; only the memory store stamps them with a Clock.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// FakeClock only moves when told to. It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
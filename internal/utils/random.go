@@ -0,0 +1,89 @@
+package utils
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"sync"
+)
+
+// Random is every source of randomness game logic uses: game codes, question
+// order and the round seeds that decide joker draws. Pass NewCryptoRandom in production and
+// NewSeededRandom in tests that need a repeatable game.
+type Random interface {
+	// Read fills p with random bytes, for seeds that must not be guessable.
+	io.Reader
+	Intn(n int) int
+	Float64() float64
+	Shuffle(n int, swap func(i, j int))
+}
+
+// NewCryptoRandom draws everything from crypto/rand. It is safe for
+// concurrent use.
+func NewCryptoRandom() Random {
+	return cryptoRandom{rand.New(cryptoSource{})}
+}
+
+type cryptoRandom struct {
+	*rand.Rand
+}
+
+// Read bypasses rand.Rand, whose Read keeps state between calls.
+func (cryptoRandom) Read(p []byte) (int, error) {
+	return crand.Read(p)
+}
+
+// cryptoSource is a math/rand source backed by crypto/rand. It keeps no
+// state, so one value can serve every goroutine.
+type cryptoSource struct{}
+
+func (cryptoSource) Seed(int64) {}
+
+func (s cryptoSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic("crypto/rand: " + err.Error())
+	}
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// NewSeededRandom returns a deterministic Random: the same seed yields the
+// same sequence. It is safe for concurrent use, though the interleaving of
+// concurrent callers is not deterministic.
+func NewSeededRandom(seed int64) Random {
+	return &seededRandom{r: rand.New(rand.NewSource(seed))}
+}
+
+type seededRandom struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (s *seededRandom) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Read(p)
+}
+
+func (s *seededRandom) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Intn(n)
+}
+
+func (s *seededRandom) Float64() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Float64()
+}
+
+func (s *seededRandom) Shuffle(n int, swap func(i, j int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.r.Shuffle(n, swap)
+}
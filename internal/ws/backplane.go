@@ -0,0 +1,83 @@
+package ws
+
+import (
+	"context"
+	"sync"
+)
+
+// Backplane carries room messages between hub instances. Every subscriber,
+// including the hub that published, receives each message. Publish stamps
+// the message with its game's next sequence number from a counter shared by
+// every instance, and each game's messages reach subscribers in sequence
+// order, so a seq means the same event on every replica. Disconnect
+// messages are delivered in order but not numbered.
+type Backplane interface {
+	Publish(ctx context.Context, msg MessageWithRoom) error
+	Subscribe(fn func(MessageWithRoom)) (unsubscribe func())
+}
+
+// subscribers is the fan-out shared by the backplane implementations.
+type subscribers struct {
+	mu     sync.Mutex
+	nextID int
+	fns    map[int]func(MessageWithRoom)
+}
+
+func (s *subscribers) add(fn func(MessageWithRoom)) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fns == nil {
+		s.fns = make(map[int]func(MessageWithRoom))
+	}
+	id := s.nextID
+	s.nextID++
+	s.fns[id] = fn
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.fns, id)
+	}
+}
+
+// dispatch holds the lock for the whole fan-out so concurrent publishers
+// cannot interleave and every subscriber sees one order.
+func (s *subscribers) dispatch(msg MessageWithRoom) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, fn := range s.fns {
+		fn(msg)
+	}
+}
+
+// LocalBackplane connects hubs inside one process. It is the single-node
+// default.
+type LocalBackplane struct {
+	mu   sync.Mutex
+	seqs map[string]int64
+	subs subscribers
+}
+
+func NewLocalBackplane() *LocalBackplane {
+	return &LocalBackplane{seqs: make(map[string]int64)}
+}
+
+// Publish numbers and dispatches under one lock, so no later message can
+// overtake an earlier one.
+func (b *LocalBackplane) Publish(ctx context.Context, msg MessageWithRoom) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !msg.Disconnect {
+		b.seqs[msg.GameCode]++
+		msg.Message.Seq = b.seqs[msg.GameCode]
+	}
+	b.subs.dispatch(msg)
+	return nil
+}
+
+func (b *LocalBackplane) Subscribe(fn func(MessageWithRoom)) func() {
+	return b.subs.add(fn)
+}
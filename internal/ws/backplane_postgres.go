@@ -0,0 +1,117 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Postgres rejects NOTIFY payloads of 8000 bytes or more.
+const maxNotifyPayload = 7999
+
+var ErrPayloadTooLarge = errors.New("ws: message too large for NOTIFY")
+
+// PostgresBackplane shares room messages between replicas with
+// LISTEN/NOTIFY. Sequence numbers come from ws_room_sequences: the counter
+// row stays locked until the NOTIFY commits, and Postgres delivers
+// notifications in commit order, so every replica sees a game's messages in
+// sequence order. Notifications sent while the listener is reconnecting are
+// lost; hubs notice the gap and send affected clients resync_required.
+type PostgresBackplane struct {
+	pool    *pgxpool.Pool
+	channel string
+	logger  *slog.Logger
+	subs    subscribers
+}
+
+func NewPostgresBackplane(pool *pgxpool.Pool, channel string, logger *slog.Logger) *PostgresBackplane {
+	return &PostgresBackplane{
+		pool:    pool,
+		channel: channel,
+		logger:  logger,
+	}
+}
+
+const nextRoomSeq = `
+INSERT INTO ws_room_sequences (game_code, seq) VALUES ($1, 1)
+ON CONFLICT (game_code) DO UPDATE SET seq = ws_room_sequences.seq + 1
+RETURNING seq`
+
+func (b *PostgresBackplane) Publish(ctx context.Context, msg MessageWithRoom) error {
+	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		if !msg.Disconnect {
+			if err := tx.QueryRow(ctx, nextRoomSeq, msg.GameCode).Scan(&msg.Message.Seq); err != nil {
+				return err
+			}
+		}
+
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if len(payload) > maxNotifyPayload {
+			return ErrPayloadTooLarge
+		}
+
+		_, err = tx.Exec(ctx, "SELECT pg_notify($1, $2)", b.channel, string(payload))
+		return err
+	})
+	if err != nil {
+		b.logger.Error("backplane publish failed", "type", msg.Message.Type, "error", err)
+	}
+	return err
+}
+
+func (b *PostgresBackplane) Subscribe(fn func(MessageWithRoom)) func() {
+	return b.subs.add(fn)
+}
+
+// Listen receives notifications until ctx is cancelled, reconnecting after
+// connection errors.
+func (b *PostgresBackplane) Listen(ctx context.Context) {
+	for ctx.Err() == nil {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		b.logger.Error("backplane listener stopped, reconnecting", "error", err)
+
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (b *PostgresBackplane) listen(ctx context.Context) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// LISTEN 綁在連線上，把連線從 pool 拿走，避免之後被別人借用
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var msg MessageWithRoom
+		if err := json.Unmarshal([]byte(notification.Payload), &msg); err != nil {
+			b.logger.Error("backplane dropped malformed message", "error", err)
+			continue
+		}
+		b.subs.dispatch(msg)
+	}
+}
@@ -0,0 +1,183 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testBackplanes returns a constructor per backplane under test: always the
+// local one, plus Postgres when TEST_DB_URL points at a migrated database,
+// which TEST_DB_REQUIRED makes mandatory. Hubs built from one constructor
+// call share the returned backplane.
+func testBackplanes(t *testing.T) map[string]func() Backplane {
+	backplanes := map[string]func() Backplane{
+		"local": func() Backplane { return NewLocalBackplane() },
+	}
+
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		if os.Getenv("TEST_DB_REQUIRED") != "" {
+			t.Fatal("TEST_DB_REQUIRED is set but TEST_DB_URL is empty")
+		}
+		return backplanes
+	}
+	backplanes["postgres"] = func() Backplane {
+		pool, err := pgxpool.New(context.Background(), url)
+		if err != nil {
+			t.Fatalf("connect to TEST_DB_URL: %v", err)
+		}
+		t.Cleanup(pool.Close)
+
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		bp := NewPostgresBackplane(pool, fmt.Sprintf("ws_test_%d", time.Now().UnixNano()), logger)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go bp.Listen(ctx)
+		waitListening(t, bp)
+		return bp
+	}
+	return backplanes
+}
+
+// waitListening publishes probes until the listener hears one.
+func waitListening(t *testing.T, bp Backplane) {
+	heard := make(chan struct{}, 1)
+	unsubscribe := bp.Subscribe(func(MessageWithRoom) {
+		select {
+		case heard <- struct{}{}:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	deadline := time.After(10 * time.Second)
+	for {
+		bp.Publish(context.Background(), MessageWithRoom{GameCode: "probe", Message: WebSocketMessage{Type: "probe"}})
+		select {
+		case <-heard:
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("backplane listener never started")
+		}
+	}
+}
+
+func startHub(t *testing.T, bp Backplane) *Hub {
+	hub := NewHub(DefaultConfig(), bp)
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+	return hub
+}
+
+func joinHub(hub *Hub, code string, playerID int64, resume bool, lastSeq int64) *Client {
+	client := &Client{
+		Send:     make(chan []byte, 256),
+		GameCode: code,
+		PlayerID: playerID,
+		Hub:      hub,
+		Resume:   resume,
+		LastSeq:  lastSeq,
+	}
+	hub.Register(client)
+	return client
+}
+
+// receive reads n messages from the client.
+func receive(t *testing.T, client *Client, n int) []WebSocketMessage {
+	t.Helper()
+	var msgs []WebSocketMessage
+	for range n {
+		select {
+		case payload := <-client.Send:
+			var msg WebSocketMessage
+			if err := json.Unmarshal(payload, &msg); err != nil {
+				t.Fatal(err)
+			}
+			msgs = append(msgs, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d messages, want %d", len(msgs), n)
+		}
+	}
+	return msgs
+}
+
+func seqs(msgs []WebSocketMessage) []int64 {
+	var out []int64
+	for _, msg := range msgs {
+		out = append(out, msg.Seq)
+	}
+	return out
+}
+
+func TestHubsShareBackplane(t *testing.T) {
+	for name, newBackplane := range testBackplanes(t) {
+		t.Run(name, func(t *testing.T) {
+			bp := newBackplane()
+			a, b := startHub(t, bp), startHub(t, bp)
+			code := fmt.Sprintf("G%d", time.Now().UnixNano())
+
+			onA := joinHub(a, code, 1, false, 0)
+			onB := joinHub(b, code, 2, false, 0)
+
+			// 兩個 replica 同時廣播，兩邊收到的順序與 seq 要一致
+			const perHub = 20
+			var wg sync.WaitGroup
+			for _, hub := range []*Hub{a, b} {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range perHub {
+						hub.BroadcastToGame(code, WebSocketMessage{Type: "event", Data: fmt.Sprintf("%p-%d", hub, i)})
+					}
+				}()
+			}
+			wg.Wait()
+
+			gotA, gotB := receive(t, onA, 2*perHub), receive(t, onB, 2*perHub)
+			for i := range gotA {
+				if gotA[i].Seq != int64(i+1) || gotA[i].Seq != gotB[i].Seq || gotA[i].Data != gotB[i].Data {
+					t.Fatalf("message %d differs: a=%+v b=%+v", i, gotA[i], gotB[i])
+				}
+			}
+
+			// 在另一個 replica 重連，補發的內容要和原本看到的一樣
+			resumed := joinHub(b, code, 1, true, 10)
+			replay := receive(t, resumed, 2*perHub-10)
+			for i, msg := range replay {
+				if msg.Seq != gotA[10+i].Seq || msg.Data != gotA[10+i].Data {
+					t.Fatalf("replay %d = %+v, want %+v", i, msg, gotA[10+i])
+				}
+			}
+
+			// 後來才啟動的 replica 沒有這段紀錄，必須要求重新同步
+			c := startHub(t, bp)
+			late := joinHub(c, code, 1, true, 5)
+			if msg := receive(t, late, 1)[0]; msg.Type != "resync_required" {
+				t.Fatalf("late replica sent %+v, want resync_required", msg)
+			}
+
+			// 之後的訊息在三個 replica 上拿到同一個 seq
+			a.BroadcastToGame(code, WebSocketMessage{Type: "event", Data: "last"})
+			want := int64(2*perHub + 1)
+			for name, client := range map[string]*Client{"a": onA, "b": onB, "late": late} {
+				if got := seqs(receive(t, client, 1)); got[0] != want {
+					t.Fatalf("%s got seq %d, want %d", name, got[0], want)
+				}
+			}
+			caughtUp := joinHub(c, code, 3, true, want-1)
+			if got := seqs(receive(t, caughtUp, 1)); got[0] != want {
+				t.Fatalf("resume on late replica got seq %d, want %d", got[0], want)
+			}
+		})
+	}
+}
@@ -1,35 +1,82 @@
 package ws
 
 import (
+	"time"
+
 	"github.com/gorilla/websocket"
 )
 
 type Client struct {
 	Conn     *websocket.Conn
 	Send     chan []byte
+	GameID   int64
 	GameCode string
 	PlayerID int64
 	Hub      *Hub
+	// Resume asks the hub to replay room events after LastSeq on register.
+	Resume  bool
+	LastSeq int64
+
+	commands CommandFunc
 }
 
+// ReadPump reads inbound commands and keeps the read deadline moving forward
+// on every pong. Commands run one at a time on a separate goroutine, so a
+// slow command never keeps the pump from reading pongs. A peer that stops answering pings
+// hits the deadline, which ends the pump and unregisters the client.
 func (c *Client) ReadPump() {
+	cfg := c.Hub.config
+	queue := make(chan Command, commandQueueSize)
+	go c.dispatch(queue)
 	defer func() {
-		c.Hub.Unregister <- c
+		close(queue)
+		c.Hub.Unregister(c)
 		c.Conn.Close()
 	}()
+
+	c.Conn.SetReadLimit(cfg.MaxMessageSize)
+	c.Conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		return c.Conn.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	})
+
 	for {
-		if _, _, err := c.Conn.NextReader(); err != nil {
+		_, data, err := c.Conn.ReadMessage()
+		if err != nil {
 			break
 		}
+		c.handle(data, queue)
 	}
 }
 
+// WritePump sends queued messages and periodic pings. Closing the connection
+// on a failed write makes ReadPump fail too, so the client is unregistered.
 func (c *Client) WritePump() {
-	defer c.Conn.Close()
-	for msg := range c.Send {
-		err := c.Conn.WriteMessage(websocket.TextMessage, msg)
-		if err != nil {
-			break
+	cfg := c.Hub.config
+	ticker := time.NewTicker(cfg.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.Conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.Send:
+			c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+			if !ok {
+				// hub 已關閉 Send
+				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.Conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
 	}
 }
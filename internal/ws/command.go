@@ -0,0 +1,109 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	commandTimeout = 10 * time.Second
+	// commandQueueSize is how many commands a client may have waiting
+	// behind the one that is running.
+	commandQueueSize = 16
+)
+
+// Command is a message sent by a client. RequestID is echoed back on the
+// matching ack or error reply.
+type Command struct {
+	Type      string          `json:"type"`
+	RequestID string          `json:"requestId"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Caller identifies the player a command came from.
+type Caller struct {
+	GameID   int64
+	GameCode string
+	PlayerID int64
+}
+
+// CommandFunc runs a command and returns the payload of its ack.
+type CommandFunc func(ctx context.Context, caller Caller, cmd Command) (any, error)
+
+// CommandError is an error reply with a status code, following HTTP
+// semantics so socket and REST clients can share error handling.
+type CommandError struct {
+	Status  int    `json:"status"`
+	Message string `json:"error"`
+}
+
+func (e *CommandError) Error() string {
+	return e.Message
+}
+
+// handle answers ping and malformed messages itself and queues every other
+// command for the configured CommandFunc. Each command gets exactly one ack
+// or error.
+func (c *Client) handle(data []byte, queue chan<- Command) {
+	var cmd Command
+	if err := json.Unmarshal(data, &cmd); err != nil || cmd.Type == "" {
+		c.reply(cmd.RequestID, nil, &CommandError{Status: http.StatusBadRequest, Message: "invalid message"})
+		return
+	}
+
+	if cmd.Type == "ping" {
+		c.reply(cmd.RequestID, map[string]any{"pong": time.Now().UnixMilli()}, nil)
+		return
+	}
+
+	if c.commands == nil {
+		c.reply(cmd.RequestID, nil, &CommandError{Status: http.StatusBadRequest, Message: "commands are not supported"})
+		return
+	}
+
+	// 排不進去就直接拒絕，不能讓讀取卡住
+	select {
+	case queue <- cmd:
+	default:
+		c.reply(cmd.RequestID, nil, &CommandError{Status: http.StatusTooManyRequests, Message: "too many commands in flight"})
+	}
+}
+
+// dispatch runs queued commands one at a time, in the order they arrived,
+// until the queue is closed.
+func (c *Client) dispatch(queue <-chan Command) {
+	for cmd := range queue {
+		c.run(cmd)
+	}
+}
+
+func (c *Client) run(cmd Command) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	result, err := c.commands(ctx, Caller{
+		GameID:   c.GameID,
+		GameCode: c.GameCode,
+		PlayerID: c.PlayerID,
+	}, cmd)
+	c.reply(cmd.RequestID, result, err)
+}
+
+func (c *Client) reply(requestID string, result any, err error) {
+	msg := WebSocketMessage{
+		Type:      "ack",
+		RequestID: requestID,
+		Data:      result,
+	}
+	if err != nil {
+		cmdErr, ok := err.(*CommandError)
+		if !ok {
+			cmdErr = &CommandError{Status: http.StatusInternalServerError, Message: err.Error()}
+		}
+		msg.Type = "error"
+		msg.Data = cmdErr
+	}
+	c.Hub.SendToClient(c, msg)
+}
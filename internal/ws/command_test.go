@@ -0,0 +1,135 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/y3933y3933/joker/internal/auth"
+)
+
+// commandReply is an ack or error as the client sees it.
+type commandReply struct {
+	Type      string          `json:"type"`
+	RequestID string          `json:"requestId"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// readReply reads until the next ack or error, skipping broadcasts.
+func readReply(t *testing.T, conn *websocket.Conn) commandReply {
+	t.Helper()
+	for {
+		var reply commandReply
+		if err := conn.ReadJSON(&reply); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		if reply.Type == "ack" || reply.Type == "error" {
+			return reply
+		}
+	}
+}
+
+func expectError(t *testing.T, reply commandReply, requestID string, status int, message string) {
+	t.Helper()
+	var cmdErr CommandError
+	if err := json.Unmarshal(reply.Data, &cmdErr); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != "error" || reply.RequestID != requestID || cmdErr.Status != status || cmdErr.Message != message {
+		t.Fatalf("got %s %q %+v, want error %q with %d %q", reply.Type, reply.RequestID, cmdErr, requestID, status, message)
+	}
+}
+
+func TestCommandDispatch(t *testing.T) {
+	hub := startHub(t, NewLocalBackplane())
+	release := make(chan struct{})
+	conn := dialGame(t, &Handler{
+		Hub: hub,
+		Session: func(*gin.Context) auth.Claims {
+			return auth.Claims{PlayerID: 7, GameID: 1, GameCode: "ABC"}
+		},
+		Commands: func(ctx context.Context, caller Caller, cmd Command) (any, error) {
+			switch cmd.Type {
+			case "slow":
+				<-release
+				return "slow done", nil
+			case "whoami":
+				return caller, nil
+			case "forbidden":
+				return nil, &CommandError{Status: http.StatusForbidden, Message: "only the host can do that"}
+			case "broken":
+				return nil, errors.New("database is down")
+			}
+			return nil, &CommandError{Status: http.StatusBadRequest, Message: "unknown command"}
+		},
+	})
+	send := func(typ, requestID string) {
+		t.Helper()
+		if err := conn.WriteJSON(Command{Type: typ, RequestID: requestID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 慢的指令還在跑的時候，讀取照樣進行，ping 也照樣回
+	send("slow", "1")
+	send("whoami", "2")
+	send("ping", "3")
+	if reply := readReply(t, conn); reply.Type != "ack" || reply.RequestID != "3" {
+		t.Fatalf("got %s %q, want the ping ack while the slow command runs", reply.Type, reply.RequestID)
+	}
+
+	// 其他指令照送來的順序執行
+	close(release)
+	if reply := readReply(t, conn); reply.Type != "ack" || reply.RequestID != "1" || string(reply.Data) != `"slow done"` {
+		t.Fatalf("got %s %q %s, want the slow ack", reply.Type, reply.RequestID, reply.Data)
+	}
+	reply := readReply(t, conn)
+	var caller Caller
+	if err := json.Unmarshal(reply.Data, &caller); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Type != "ack" || reply.RequestID != "2" || caller != (Caller{GameID: 1, GameCode: "ABC", PlayerID: 7}) {
+		t.Fatalf("got %s %q %+v, want the caller's ack", reply.Type, reply.RequestID, caller)
+	}
+
+	send("forbidden", "4")
+	expectError(t, readReply(t, conn), "4", http.StatusForbidden, "only the host can do that")
+	send("broken", "5")
+	expectError(t, readReply(t, conn), "5", http.StatusInternalServerError, "database is down")
+	send("teleport", "6")
+	expectError(t, readReply(t, conn), "6", http.StatusBadRequest, "unknown command")
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, readReply(t, conn), "", http.StatusBadRequest, "invalid message")
+	if err := conn.WriteJSON(map[string]string{"requestId": "7"}); err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, readReply(t, conn), "7", http.StatusBadRequest, "invalid message")
+}
+
+func TestCommandsNotSupported(t *testing.T) {
+	hub := startHub(t, NewLocalBackplane())
+	conn := dialGame(t, &Handler{
+		Hub: hub,
+		Session: func(*gin.Context) auth.Claims {
+			return auth.Claims{PlayerID: 7, GameID: 1, GameCode: "ABC"}
+		},
+	})
+
+	if err := conn.WriteJSON(Command{Type: "ping", RequestID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if reply := readReply(t, conn); reply.Type != "ack" || reply.RequestID != "1" {
+		t.Fatalf("got %s %q, want the ping ack", reply.Type, reply.RequestID)
+	}
+	if err := conn.WriteJSON(Command{Type: "draw_card", RequestID: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, readReply(t, conn), "2", http.StatusBadRequest, "commands are not supported")
+}
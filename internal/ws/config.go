@@ -0,0 +1,27 @@
+package ws
+
+import "time"
+
+// Config controls connection liveness. The server pings every PingPeriod and
+// drops a client that has not answered within PongWait. Each room keeps its
+// last ReplayBufferSize messages for clients that reconnect, and is dropped
+// once it has had no clients for RoomIdleTTL.
+type Config struct {
+	WriteWait        time.Duration
+	PongWait         time.Duration
+	PingPeriod       time.Duration
+	MaxMessageSize   int64
+	ReplayBufferSize int
+	RoomIdleTTL      time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		WriteWait:        10 * time.Second,
+		PongWait:         60 * time.Second,
+		PingPeriod:       54 * time.Second,
+		MaxMessageSize:   4096,
+		ReplayBufferSize: 256,
+		RoomIdleTTL:      30 * time.Minute,
+	}
+}
@@ -1,11 +1,13 @@
 package ws
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/y3933y3933/joker/internal/auth"
 )
 
 var upgrader = websocket.Upgrader{
@@ -14,11 +16,39 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-func ServeWS(hub *Hub, c *gin.Context) {
+// SnapshotFunc builds the game state a player sees right after connecting.
+type SnapshotFunc func(ctx context.Context, gameCode string, playerID int64) (any, error)
+
+// SessionFunc returns the claims the authentication middleware in front of
+// ServeWS verified.
+type SessionFunc func(c *gin.Context) auth.Claims
+
+type Handler struct {
+	Hub     *Hub
+	Session SessionFunc
+	// Snapshot, when set, is pushed to every new connection as state_sync.
+	// The connection is closed with an error when it fails.
+	Snapshot SnapshotFunc
+	// Commands, when set, runs inbound commands other than ping.
+	Commands CommandFunc
+}
+
+func (h *Handler) ServeWS(c *gin.Context) {
 	gameCode := c.Param("code")
+	// token 已由前面的 middleware 驗證過，也確認過是這場遊戲的成員
+	claims := h.Session(c)
 
-	playerIDStr := c.Query("player_id")
-	playerID, _ := strconv.ParseInt(playerIDStr, 10, 64)
+	// 斷線重連時帶上最後收到的 seq，hub 會補發漏掉的訊息
+	var lastSeq int64
+	lastSeqStr, resume := c.GetQuery("last_seq")
+	if resume {
+		var err error
+		lastSeq, err = strconv.ParseInt(lastSeqStr, 10, 64)
+		if err != nil || lastSeq < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last_seq"})
+			return
+		}
+	}
 
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -28,13 +58,34 @@ func ServeWS(hub *Hub, c *gin.Context) {
 	client := &Client{
 		Conn:     conn,
 		Send:     make(chan []byte, 256),
+		GameID:   claims.GameID,
 		GameCode: gameCode,
-		Hub:      hub,
-		PlayerID: playerID,
+		Hub:      h.Hub,
+		PlayerID: claims.PlayerID,
+		Resume:   resume,
+		LastSeq:  lastSeq,
+		commands: h.Commands,
 	}
 
-	hub.Register <- client
+	h.Hub.Register(client)
 
 	go client.ReadPump()
 	go client.WritePump()
+
+	// 先註冊再取快照，之後的廣播都不會漏掉。快照可能比它之前送出的事件
+	// 還新，快照裡帶的 seq 讓前端知道哪些事件已經包含在裡面
+	if h.Snapshot != nil {
+		snapshot, err := h.Snapshot(c.Request.Context(), gameCode, claims.PlayerID)
+		if err != nil {
+			h.Hub.CloseClient(client, WebSocketMessage{
+				Type: "error",
+				Data: &CommandError{Status: http.StatusInternalServerError, Message: "failed to load game state"},
+			})
+			return
+		}
+		h.Hub.SendToClient(client, WebSocketMessage{
+			Type: "state_sync",
+			Data: snapshot,
+		})
+	}
 }
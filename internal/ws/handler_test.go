@@ -0,0 +1,163 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/y3933y3933/joker/internal/auth"
+)
+
+// TestServeWSUsesSession checks that ServeWS takes the player from the
+// session the middleware verified, even when no ?token= was sent.
+func TestServeWSUsesSession(t *testing.T) {
+	hub := startHub(t, NewLocalBackplane())
+	dialGame(t, &Handler{
+		Hub: hub,
+		Session: func(*gin.Context) auth.Claims {
+			return auth.Claims{PlayerID: 7, GameID: 1, GameCode: "ABC"}
+		},
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !hub.ConnectedPlayers("ABC")[7] {
+		if time.Now().After(deadline) {
+			t.Fatal("player 7 never joined the room")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// dialGame serves handler and connects to it as the session's player.
+func dialGame(t *testing.T, handler *Handler) *websocket.Conn {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws/games/:code", handler.ServeWS)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/games/ABC", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestServeWSSnapshotError(t *testing.T) {
+	hub := startHub(t, NewLocalBackplane())
+	conn := dialGame(t, &Handler{
+		Hub: hub,
+		Session: func(*gin.Context) auth.Claims {
+			return auth.Claims{PlayerID: 7, GameID: 1, GameCode: "ABC"}
+		},
+		Snapshot: func(context.Context, string, int64) (any, error) {
+			return nil, errors.New("database is down")
+		},
+	})
+
+	// 拿不到快照就回錯誤並關掉連線，不留一條沒有狀態的連線
+	var msg struct {
+		Type string       `json:"type"`
+		Data CommandError `json:"data"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if msg.Type != "error" || msg.Data.Status != http.StatusInternalServerError {
+		t.Fatalf("got %+v, want an error with status 500", msg)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNoStatusReceived, websocket.CloseNormalClosure) {
+		t.Fatalf("got %v, want the connection closed", err)
+	}
+}
+
+// TestHeartbeatDropsSilentClient checks that a peer which stops answering
+// pings is unregistered after PongWait while one that answers stays.
+func TestHeartbeatDropsSilentClient(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PingPeriod = 50 * time.Millisecond
+	cfg.PongWait = 150 * time.Millisecond
+	hub := NewHub(cfg, NewLocalBackplane())
+	dropped := make(chan int64, 1)
+	hub.OnDisconnect(func(_ string, playerID int64) {
+		dropped <- playerID
+	})
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws/games/:code", (&Handler{
+		Hub: hub,
+		Session: func(c *gin.Context) auth.Claims {
+			id, _ := strconv.ParseInt(c.Query("player"), 10, 64)
+			return auth.Claims{PlayerID: id, GameID: 1, GameCode: "ABC"}
+		},
+	}).ServeWS)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	dial := func(playerID int64) *websocket.Conn {
+		url := fmt.Sprintf("ws%s/ws/games/ABC?player=%d", strings.TrimPrefix(srv.URL, "http"), playerID)
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+
+	// 讀取時 gorilla 會自動回 pong；不讀的那條連線永遠不回
+	alive := dial(1)
+	messages := make(chan WebSocketMessage, 16)
+	go func() {
+		for {
+			var msg WebSocketMessage
+			if err := alive.ReadJSON(&msg); err != nil {
+				close(messages)
+				return
+			}
+			messages <- msg
+		}
+	}()
+	dial(2)
+
+	select {
+	case id := <-dropped:
+		if id != 2 {
+			t.Fatalf("player %d dropped, want the silent player 2", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the silent client was never unregistered")
+	}
+
+	timeout := time.After(5 * time.Second)
+	for announced := false; !announced; {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				t.Fatal("the answering client was disconnected")
+			}
+			data, _ := msg.Data.(map[string]any)
+			announced = msg.Type == "player_disconnected" && data["playerId"] == float64(2)
+		case <-timeout:
+			t.Fatal("player_disconnected was never broadcast")
+		}
+	}
+
+	// 多等幾個 PongWait，有回 pong 的連線要一直留著
+	time.Sleep(3 * cfg.PongWait)
+	if players := hub.ConnectedPlayers("ABC"); !players[1] || players[2] {
+		t.Fatalf("connected players %v, want only player 1", players)
+	}
+}
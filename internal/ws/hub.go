@@ -2,99 +2,445 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
+	"time"
 )
 
+const publishTimeout = 5 * time.Second
+
+// Hub owns every room. All room state lives in the Run goroutine and is only
+// touched from there: registration, removal and delivery arrive over
+// channels, so no locks are needed and each client's Send channel is written
+// and closed by a single goroutine. A client's Send is closed exactly once,
+// when the hub drops it from its room.
+//
+// Every message sent to a room, private ones included, carries the sequence
+// number the backplane gave it, so a reconnecting client can ask any replica
+// for what it missed. Only SendToClient, CloseClient and BroadcastLocal,
+// which stay on this hub, go unnumbered.
+//
+// Room messages travel through the Backplane before delivery, so hubs on
+// several replicas sharing one backplane serve the same rooms. Presence
+// (ConnectedPlayers, OnlinePlayers, player_disconnected) only covers this
+// hub's sockets.
 type Hub struct {
-	mu         sync.RWMutex
-	rooms      map[string]map[*Client]bool
-	Register   chan *Client
-	Unregister chan *Client
-	Broadcast  chan MessageWithRoom
+	config      Config
+	backplane   Backplane
+	unsubscribe func()
+	rooms       map[string]*room
+	register    chan *Client
+	unregister  chan *Client
+	outbound    chan MessageWithRoom
+	presence    chan presenceQuery
+	seqs        chan seqQuery
+	done        chan struct{}
+	stopOnce    sync.Once
+	// onDisconnect is called when a player's last socket on this hub closes.
+	onDisconnect DisconnectFunc
 }
 
+// DisconnectFunc is told which player dropped. It runs on its own goroutine.
+type DisconnectFunc func(gameCode string, playerID int64)
+
+// MessageWithRoom is a message addressed to a room, or to a single player in
+// that room when PlayerID is not zero.
 type MessageWithRoom struct {
-	GameCode string
-	Message  WebSocketMessage
+	GameCode string           `json:"gameCode"`
+	PlayerID int64            `json:"playerId,omitempty"`
+	Message  WebSocketMessage `json:"message"`
+	// Disconnect closes every socket PlayerID holds in the room instead of
+	// delivering a message, or, for a message to one client, closes that
+	// socket after delivering it. It is not sequenced.
+	Disconnect bool `json:"disconnect,omitempty"`
+	// client targets one connection; such messages skip sequencing and the
+	// replay buffer.
+	client *Client
+	// local messages go to this hub's sockets only, also unsequenced.
+	local bool
+}
+
+// presenceQuery asks for the players in one room, or in every room when
+// gameCode is empty.
+type presenceQuery struct {
+	gameCode string
+	reply    chan map[int64]bool
+}
+
+// seqQuery asks for the seq of the last event a room recorded.
+type seqQuery struct {
+	gameCode string
+	reply    chan int64
 }
 
 type WebSocketMessage struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
+	Type      string      `json:"type"`
+	Seq       int64       `json:"seq,omitempty"`
+	RequestID string      `json:"requestId,omitempty"`
+	Data      interface{} `json:"data"`
 }
 
-func NewHub() *Hub {
-	return &Hub{
-		mu:         sync.RWMutex{},
-		rooms:      make(map[string]map[*Client]bool),
-		Register:   make(chan *Client),
-		Unregister: make(chan *Client),
-		Broadcast:  make(chan MessageWithRoom),
+func NewHub(config Config, backplane Backplane) *Hub {
+	h := &Hub{
+		config:     config,
+		backplane:  backplane,
+		rooms:      make(map[string]*room),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		outbound:   make(chan MessageWithRoom, 256),
+		presence:   make(chan presenceQuery),
+		seqs:       make(chan seqQuery),
+		done:       make(chan struct{}),
 	}
+	h.unsubscribe = backplane.Subscribe(h.enqueue)
+	return h
 }
 
 func (h *Hub) Run() {
+	sweep := time.NewTicker(h.config.RoomIdleTTL)
+	defer sweep.Stop()
+
 	for {
 		select {
-		case client := <-h.Register:
-			h.mu.Lock()
-			if h.rooms[client.GameCode] == nil {
-				h.rooms[client.GameCode] = make(map[*Client]bool)
+		case client := <-h.register:
+			h.add(client)
+
+		case client := <-h.unregister:
+			h.remove(client)
+
+		case msg := <-h.outbound:
+			h.deliver(msg)
+
+		case q := <-h.presence:
+			connected := make(map[int64]bool)
+			for code, r := range h.rooms {
+				if q.gameCode != "" && code != q.gameCode {
+					continue
+				}
+				for client := range r.clients {
+					connected[client.PlayerID] = true
+				}
 			}
-			h.rooms[client.GameCode][client] = true
-			h.mu.Unlock()
-
-		case client := <-h.Unregister:
-			h.mu.Lock()
-			if clients, ok := h.rooms[client.GameCode]; ok {
-				if _, exists := clients[client]; exists {
-					delete(clients, client)
-					close(client.Send)
+			q.reply <- connected
+
+		case q := <-h.seqs:
+			var seq int64
+			if r, ok := h.rooms[q.gameCode]; ok {
+				seq = r.seq
+			}
+			q.reply <- seq
+
+		case now := <-sweep.C:
+			// 房間清空超過 RoomIdleTTL 才丟掉，保留重連補發的紀錄
+			for code, r := range h.rooms {
+				if len(r.clients) == 0 && now.Sub(r.emptySince) >= h.config.RoomIdleTTL {
+					delete(h.rooms, code)
 				}
 			}
-			h.mu.Unlock()
-
-		case msg := <-h.Broadcast:
-			h.mu.RLock()
-			clients := h.rooms[msg.GameCode]
-			h.mu.RUnlock()
-
-			payload, _ := json.Marshal(msg.Message)
-			for client := range clients {
-				select {
-				case client.Send <- payload:
-				default:
+
+		case <-h.done:
+			for _, r := range h.rooms {
+				for client := range r.clients {
 					close(client.Send)
-					delete(clients, client)
 				}
 			}
+			h.rooms = nil
+			return
 		}
 	}
 }
 
+// OnDisconnect sets the function told about players who dropped. Call it
+// before Run.
+func (h *Hub) OnDisconnect(fn DisconnectFunc) {
+	h.onDisconnect = fn
+}
+
+// Stop shuts the hub down and closes every client's Send channel.
+func (h *Hub) Stop() {
+	h.stopOnce.Do(func() {
+		h.unsubscribe()
+		close(h.done)
+	})
+}
+
+// Register adds the client to its room. If the hub has stopped the client's
+// Send channel is closed straight away so its write pump exits.
+func (h *Hub) Register(client *Client) {
+	select {
+	case h.register <- client:
+	case <-h.done:
+		close(client.Send)
+	}
+}
+
+// Unregister removes the client from its room. It is safe to call more than
+// once and after the hub has already dropped the client.
+func (h *Hub) Unregister(client *Client) {
+	select {
+	case h.unregister <- client:
+	case <-h.done:
+	}
+}
+
 func (h *Hub) BroadcastToGame(code string, msg WebSocketMessage) {
-	h.Broadcast <- MessageWithRoom{
+	h.publish(MessageWithRoom{
 		GameCode: code,
 		Message:  msg,
-	}
+	})
 }
 
 func (h *Hub) SendToPlayer(code string, targetPlayerID int64, msg WebSocketMessage) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	clients := h.rooms[code]
-	payload, _ := json.Marshal(msg)
-
-	for client := range clients {
-		if client.PlayerID == targetPlayerID {
-			select {
-			case client.Send <- payload:
-			default:
-				close(client.Send)
-				delete(clients, client)
+	h.publish(MessageWithRoom{
+		GameCode: code,
+		PlayerID: targetPlayerID,
+		Message:  msg,
+	})
+}
+
+// DisconnectPlayer closes the player's sockets in the game's room on every
+// hub sharing the backplane, e.g. once they were removed from the game.
+func (h *Hub) DisconnectPlayer(code string, playerID int64) {
+	h.publish(MessageWithRoom{
+		GameCode:   code,
+		PlayerID:   playerID,
+		Disconnect: true,
+	})
+}
+
+// SendToClient sends msg to a single local connection without sequencing
+// it or going through the backplane.
+func (h *Hub) SendToClient(client *Client, msg WebSocketMessage) {
+	h.enqueue(MessageWithRoom{
+		GameCode: client.GameCode,
+		Message:  msg,
+		client:   client,
+	})
+}
+
+// CloseClient sends msg to one connection and then closes it, in order with
+// everything else the hub sends it.
+func (h *Hub) CloseClient(client *Client, msg WebSocketMessage) {
+	h.enqueue(MessageWithRoom{
+		GameCode:   client.GameCode,
+		Message:    msg,
+		Disconnect: true,
+		client:     client,
+	})
+}
+
+// BroadcastLocal sends msg to this hub's sockets in the game's room without
+// sequencing, buffering or publishing it. It is meant for transient updates
+// that every replica produces for its own sockets, such as timer ticks.
+func (h *Hub) BroadcastLocal(code string, msg WebSocketMessage) {
+	h.enqueue(MessageWithRoom{
+		GameCode: code,
+		Message:  msg,
+		local:    true,
+	})
+}
+
+// ConnectedPlayers returns the IDs of players with at least one open socket
+// in the game's room.
+func (h *Hub) ConnectedPlayers(code string) map[int64]bool {
+	return h.queryPresence(code)
+}
+
+// OnlinePlayers returns the IDs of players with at least one open socket on
+// this hub, in any room.
+func (h *Hub) OnlinePlayers() map[int64]bool {
+	return h.queryPresence("")
+}
+
+// LastSeq returns the seq of the last event this hub delivered to the game's
+// room, or 0 when it has none. Every event up to it was committed before it
+// was published, so state read after calling LastSeq already includes them.
+func (h *Hub) LastSeq(code string) int64 {
+	reply := make(chan int64, 1)
+	select {
+	case h.seqs <- seqQuery{gameCode: code, reply: reply}:
+		return <-reply
+	case <-h.done:
+		return 0
+	}
+}
+
+func (h *Hub) queryPresence(code string) map[int64]bool {
+	reply := make(chan map[int64]bool, 1)
+	select {
+	case h.presence <- presenceQuery{gameCode: code, reply: reply}:
+		return <-reply
+	case <-h.done:
+		return map[int64]bool{}
+	}
+}
+
+func (h *Hub) publish(msg MessageWithRoom) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	h.backplane.Publish(ctx, msg)
+}
+
+// enqueue hands a message to the Run goroutine. The backplane calls it for
+// every room message, including the ones this hub published.
+func (h *Hub) enqueue(msg MessageWithRoom) {
+	select {
+	case h.outbound <- msg:
+	case <-h.done:
+	}
+}
+
+func (h *Hub) room(code string) *room {
+	r, ok := h.rooms[code]
+	if !ok {
+		r = newRoom()
+		h.rooms[code] = r
+	}
+	return r
+}
+
+// add joins the client to its room. A resuming client first gets every
+// buffered event after its LastSeq, or resync_required when the buffer no
+// longer reaches back that far.
+func (h *Hub) add(client *Client) {
+	r := h.room(client.GameCode)
+	r.clients[client] = true
+
+	if !client.Resume {
+		return
+	}
+
+	events, complete := r.missed(client.LastSeq, client.PlayerID)
+	if !complete {
+		payload, err := json.Marshal(WebSocketMessage{
+			Type: "resync_required",
+			Data: map[string]any{
+				"lastSeq": r.seq,
+			},
+		})
+		if err == nil {
+			h.send(r, client, payload)
+		}
+	}
+	for _, ev := range events {
+		if !h.send(r, client, ev.payload) {
+			return
+		}
+	}
+}
+
+// remove drops the client from its room and closes its Send channel. Only
+// the Run goroutine calls it, and the membership check makes it idempotent.
+// When that was the player's last connection the room is told the player
+// dropped.
+func (h *Hub) remove(client *Client) {
+	r, ok := h.rooms[client.GameCode]
+	if !ok || !r.clients[client] {
+		return
+	}
+
+	delete(r.clients, client)
+	close(client.Send)
+
+	if len(r.clients) == 0 {
+		r.emptySince = time.Now()
+	}
+
+	for other := range r.clients {
+		if other.PlayerID == client.PlayerID {
+			return
+		}
+	}
+	if h.onDisconnect != nil {
+		go h.onDisconnect(client.GameCode, client.PlayerID)
+	}
+	// 透過 backplane 發送；在 Run 裡同步 publish 可能等到自己的 outbound
+	go h.publish(MessageWithRoom{
+		GameCode: client.GameCode,
+		Message: WebSocketMessage{
+			Type: "player_disconnected",
+			Data: map[string]any{
+				"playerId": client.PlayerID,
+			},
+		},
+	})
+}
+
+func (h *Hub) deliver(msg MessageWithRoom) {
+	if msg.Disconnect && msg.client != nil {
+		if r, ok := h.rooms[msg.GameCode]; ok {
+			payload, err := json.Marshal(msg.Message)
+			if err == nil {
+				h.send(r, msg.client, payload)
+			}
+			h.remove(msg.client)
+		}
+		return
+	}
+	if msg.Disconnect {
+		if r, ok := h.rooms[msg.GameCode]; ok {
+			for client := range r.clients {
+				if client.PlayerID == msg.PlayerID {
+					h.remove(client)
+				}
 			}
 		}
+		return
+	}
+
+	if msg.local {
+		r, ok := h.rooms[msg.GameCode]
+		if !ok {
+			return
+		}
+		payload, err := json.Marshal(msg.Message)
+		if err != nil {
+			return
+		}
+		for client := range r.clients {
+			h.send(r, client, payload)
+		}
+		return
+	}
+
+	r := h.room(msg.GameCode)
+
+	if msg.client != nil {
+		payload, err := json.Marshal(msg.Message)
+		if err == nil {
+			h.send(r, msg.client, payload)
+		}
+		return
+	}
+
+	payload, err := json.Marshal(msg.Message)
+	if err != nil {
+		return
+	}
+	ev := r.record(msg.Message.Seq, msg.PlayerID, payload, h.config.ReplayBufferSize)
+
+	for client := range r.clients {
+		if msg.PlayerID != 0 && client.PlayerID != msg.PlayerID {
+			continue
+		}
+		h.send(r, client, ev.payload)
+	}
+}
+
+// send queues payload without blocking the hub. A client whose buffer is
+// full is too slow to keep up and gets dropped; its WritePump exits once
+// Send is closed.
+func (h *Hub) send(r *room, client *Client, payload []byte) bool {
+	if !r.clients[client] {
+		return false
+	}
+	select {
+	case client.Send <- payload:
+		return true
+	default:
+		h.remove(client)
+		return false
 	}
 }
@@ -0,0 +1,180 @@
+package ws
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// drain reads client.Send until the hub closes it and reports on the
+// returned channel once it has.
+func drain(client *Client) <-chan struct{} {
+	closed := make(chan struct{})
+	go func() {
+		for range client.Send {
+		}
+		close(closed)
+	}()
+	return closed
+}
+
+func waitClosed(t *testing.T, closed <-chan struct{}, who string) {
+	t.Helper()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("%s: Send was never closed", who)
+	}
+}
+
+// TestHubConcurrentJoinsLeavesAndBroadcasts is meant for go test -race. A
+// Send closed twice panics, one never closed hangs its drain.
+func TestHubConcurrentJoinsLeavesAndBroadcasts(t *testing.T) {
+	hub := NewHub(DefaultConfig(), NewLocalBackplane())
+	go hub.Run()
+
+	const (
+		games      = 3
+		clients    = 60
+		broadcasts = 40
+	)
+
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			code := fmt.Sprintf("G%d", i%games)
+			// 有的 buffer 很小，會被 hub 當成慢速連線踢掉
+			client := &Client{
+				Send:     make(chan []byte, 1+i%4),
+				GameCode: code,
+				PlayerID: int64(i % 7),
+				Hub:      hub,
+			}
+			hub.Register(client)
+			closed := drain(client)
+
+			for j := range broadcasts {
+				hub.BroadcastToGame(code, WebSocketMessage{Type: "broadcast"})
+				hub.SendToPlayer(code, int64(j%7), WebSocketMessage{Type: "private"})
+				hub.SendToClient(client, WebSocketMessage{Type: "direct"})
+			}
+
+			hub.Unregister(client)
+			hub.Unregister(client)
+			waitClosed(t, closed, fmt.Sprintf("client %d", i))
+		}()
+	}
+	wg.Wait()
+
+	// 沒有離開的連線在 Stop 時關閉
+	var left []<-chan struct{}
+	for i := range games {
+		client := &Client{Send: make(chan []byte, 8), GameCode: fmt.Sprintf("G%d", i), PlayerID: 1, Hub: hub}
+		hub.Register(client)
+		left = append(left, drain(client))
+	}
+	hub.Stop()
+	for i, closed := range left {
+		waitClosed(t, closed, fmt.Sprintf("remaining client %d", i))
+	}
+
+	// 停止後註冊的連線也要立刻關閉，而且只關一次
+	late := &Client{Send: make(chan []byte, 1), GameCode: "G0", PlayerID: 1, Hub: hub}
+	hub.Register(late)
+	hub.Unregister(late)
+	waitClosed(t, drain(late), "late client")
+}
+
+func TestDisconnectPlayer(t *testing.T) {
+	bp := NewLocalBackplane()
+	a, b := startHub(t, bp), startHub(t, bp)
+
+	// 玩家 1 在兩個 replica 各有一條連線
+	kickedA := joinHub(a, "G", 1, false, 0)
+	kickedB := joinHub(b, "G", 1, false, 0)
+	other := joinHub(b, "G", 2, false, 0)
+	closedA, closedB := drain(kickedA), drain(kickedB)
+
+	a.BroadcastToGame("G", WebSocketMessage{Type: "before"})
+	a.DisconnectPlayer("G", 1)
+	waitClosed(t, closedA, "socket on hub a")
+	waitClosed(t, closedB, "socket on hub b")
+
+	// 其他玩家照常收到訊息，關連線不佔 seq
+	a.BroadcastToGame("G", WebSocketMessage{Type: "after"})
+	for want := int64(1); ; want++ {
+		msg := receive(t, other, 1)[0]
+		if msg.Seq != want {
+			t.Fatalf("got %s with seq %d, want seq %d", msg.Type, msg.Seq, want)
+		}
+		if msg.Type == "after" {
+			break
+		}
+	}
+}
+
+func TestBroadcastLocal(t *testing.T) {
+	bp := NewLocalBackplane()
+	a, b := startHub(t, bp), startHub(t, bp)
+	onA := joinHub(a, "G", 1, false, 0)
+	onB := joinHub(b, "G", 2, false, 0)
+
+	// 只送給本機的連線，不編號也不進補發紀錄
+	a.BroadcastLocal("G", WebSocketMessage{Type: "tick"})
+	a.BroadcastToGame("G", WebSocketMessage{Type: "event"})
+
+	if got := receive(t, onA, 2); got[0].Type != "tick" || got[0].Seq != 0 || got[1].Seq != 1 {
+		t.Fatalf("hub a got %+v, want an unnumbered tick then seq 1", got)
+	}
+	if got := receive(t, onB, 1); got[0].Type != "event" || got[0].Seq != 1 {
+		t.Fatalf("hub b got %+v, want only the event with seq 1", got)
+	}
+
+	resumed := joinHub(a, "G", 3, true, 0)
+	if got := receive(t, resumed, 1); got[0].Type != "event" {
+		t.Fatalf("replay started with %+v, want the event", got[0])
+	}
+}
+
+func TestLastSeq(t *testing.T) {
+	hub := startHub(t, NewLocalBackplane())
+	if got := hub.LastSeq("G"); got != 0 {
+		t.Fatalf("unknown room: got seq %d, want 0", got)
+	}
+
+	client := joinHub(hub, "G", 1, false, 0)
+	hub.BroadcastToGame("G", WebSocketMessage{Type: "first"})
+	hub.SendToPlayer("G", 1, WebSocketMessage{Type: "private"})
+	hub.BroadcastLocal("G", WebSocketMessage{Type: "tick"})
+	receive(t, client, 3)
+
+	// 不編號的訊息不算
+	if got := hub.LastSeq("G"); got != 2 {
+		t.Fatalf("got seq %d, want 2", got)
+	}
+}
+
+func TestCloseClient(t *testing.T) {
+	hub := startHub(t, NewLocalBackplane())
+	closing := joinHub(hub, "G", 1, false, 0)
+	other := joinHub(hub, "G", 1, false, 0)
+
+	hub.BroadcastToGame("G", WebSocketMessage{Type: "before"})
+	hub.CloseClient(closing, WebSocketMessage{Type: "error"})
+
+	// 先收到前面的訊息和錯誤，再被關掉；同一個玩家的其他連線不受影響
+	if got := receive(t, closing, 2); got[0].Type != "before" || got[1].Type != "error" {
+		t.Fatalf("got %+v, want before then error", got)
+	}
+	waitClosed(t, drain(closing), "closed client")
+	if !hub.ConnectedPlayers("G")[1] {
+		t.Fatal("player 1 lost its other socket")
+	}
+	if got := receive(t, other, 1); got[0].Type != "before" {
+		t.Fatalf("other socket got %+v, want before", got)
+	}
+}
@@ -0,0 +1,69 @@
+package ws
+
+import "time"
+
+// event is a delivered message kept for replay. playerID is non-zero for
+// private messages, which are only replayed to that player.
+type event struct {
+	seq      int64
+	playerID int64
+	payload  []byte
+}
+
+// room holds the connected clients of one game and a bounded history of
+// what was sent to it. A room outlives its clients for RoomIdleTTL so a
+// player whose socket dropped can still resume.
+type room struct {
+	clients    map[*Client]bool
+	seq        int64
+	history    []event
+	emptySince time.Time
+}
+
+func newRoom() *room {
+	return &room{
+		clients:    make(map[*Client]bool),
+		emptySince: time.Now(),
+	}
+}
+
+// record appends the event the backplane numbered seq, dropping the oldest
+// one once the buffer is full. A seq that does not follow the previous one
+// means this hub missed messages, so the older history is dropped too and
+// clients that need the gap get resync_required.
+func (r *room) record(seq, playerID int64, payload []byte, limit int) event {
+	if seq != r.seq+1 {
+		r.history = r.history[:0]
+	}
+	r.seq = seq
+
+	ev := event{seq: seq, playerID: playerID, payload: payload}
+	if limit > 0 {
+		if len(r.history) >= limit {
+			r.history = append(r.history[:0], r.history[len(r.history)-limit+1:]...)
+		}
+		r.history = append(r.history, ev)
+	}
+	return ev
+}
+
+// missed returns the buffered events after lastSeq visible to playerID, and
+// whether the buffer still reaches back far enough to cover the gap. A
+// lastSeq past the room's own sequence means the room was reset since the
+// client last heard from it, so nothing it knows can be trusted.
+func (r *room) missed(lastSeq, playerID int64) ([]event, bool) {
+	complete := lastSeq == r.seq ||
+		(lastSeq < r.seq && len(r.history) > 0 && r.history[0].seq <= lastSeq+1)
+
+	var events []event
+	for _, ev := range r.history {
+		if ev.seq <= lastSeq {
+			continue
+		}
+		if ev.playerID != 0 && ev.playerID != playerID {
+			continue
+		}
+		events = append(events, ev)
+	}
+	return events, complete
+}
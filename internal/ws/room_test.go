@@ -0,0 +1,46 @@
+package ws
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestRoomMissed(t *testing.T) {
+	// 六則訊息，buffer 只留最後四則（seq 3..6）；seq 4 是給玩家 2 的私訊
+	r := newRoom()
+	for i := 1; i <= 6; i++ {
+		var playerID int64
+		if i == 4 {
+			playerID = 2
+		}
+		r.record(int64(i), playerID, []byte(strconv.Itoa(i)), 4)
+	}
+
+	tests := []struct {
+		name     string
+		lastSeq  int64
+		playerID int64
+		want     []int64
+		complete bool
+	}{
+		{"up to date", 6, 1, nil, true},
+		{"inside the buffer", 3, 1, []int64{5, 6}, true},
+		{"private message for the viewer", 3, 2, []int64{4, 5, 6}, true},
+		{"first buffered event", 2, 1, []int64{3, 5, 6}, true},
+		{"older than the buffer", 1, 1, []int64{3, 5, 6}, false},
+		{"ahead of a reset room", 9, 1, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, complete := r.missed(tt.lastSeq, tt.playerID)
+			var got []int64
+			for _, ev := range events {
+				got = append(got, ev.seq)
+			}
+			if !slices.Equal(got, tt.want) || complete != tt.complete {
+				t.Fatalf("missed(%d) = %v, %v; want %v, %v", tt.lastSeq, got, complete, tt.want, tt.complete)
+			}
+		})
+	}
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/y3933y3933/joker/internal/app"
+	"github.com/y3933y3933/joker/internal/questions"
 	"github.com/y3933y3933/joker/internal/routes"
 )
 
 func main() {
+	// 題庫匯入/匯出：go run . questions import|export ...
+	if len(os.Args) > 1 && os.Args[1] == "questions" {
+		if err := questions.RunCLI(context.Background(), os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app, err := app.NewApplication()
 	if err != nil {
 		panic(err)
@@ -19,7 +30,7 @@ func main() {
 
 	err = r.Run(fmt.Sprintf(":%d", app.Config.Port))
 	if err != nil {
-		app.Logger.Error("failed to start server", err)
+		app.Logger.Error("failed to start server", "error", err)
 		os.Exit(1)
 	}
 
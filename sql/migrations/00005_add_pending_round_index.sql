@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- earlier versions never closed a round before creating the next one, so a
+-- game may hold several pending rounds; only the newest one stays open
+UPDATE rounds r
+SET status = 'done'
+WHERE r.status = 'pending'
+  AND EXISTS (
+      SELECT 1 FROM rounds newer
+      WHERE newer.game_id = r.game_id
+        AND newer.status = 'pending'
+        AND newer.id > r.id
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS rounds_one_pending_per_game
+    ON rounds (game_id)
+    WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS rounds_one_pending_per_game;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- game_id is set on a game's private deck built from inline questions
+CREATE TABLE IF NOT EXISTS decks (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    game_id BIGINT REFERENCES games(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS deck_questions (
+    deck_id BIGINT NOT NULL REFERENCES decks(id) ON DELETE CASCADE,
+    question_id BIGINT NOT NULL REFERENCES questions(id),
+    PRIMARY KEY (deck_id, question_id)
+);
+
+-- questions with a game_id are private to that game and never join the
+-- global pool
+ALTER TABLE questions ADD COLUMN game_id BIGINT REFERENCES games(id) ON DELETE CASCADE;
+ALTER TABLE games ADD COLUMN deck_id BIGINT REFERENCES decks(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE games DROP COLUMN IF EXISTS deck_id;
+ALTER TABLE questions DROP COLUMN IF EXISTS game_id;
+DROP TABLE IF EXISTS deck_questions;
+DROP TABLE IF EXISTS decks;
+-- +goose StatementEnd
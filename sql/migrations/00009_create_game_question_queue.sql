@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- each game walks a shuffled copy of its question pool; question_cursor is
+-- the next position to draw, and the queue is rebuilt once it runs out
+CREATE TABLE IF NOT EXISTS game_question_queue (
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    question_id BIGINT NOT NULL REFERENCES questions(id),
+    PRIMARY KEY (game_id, position)
+);
+
+ALTER TABLE games ADD COLUMN question_cursor INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE games DROP COLUMN IF EXISTS question_cursor;
+DROP TABLE IF EXISTS game_question_queue;
+-- +goose StatementEnd
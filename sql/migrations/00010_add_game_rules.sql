@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE games
+    ADD COLUMN draw_mode TEXT NOT NULL DEFAULT 'random' CHECK (draw_mode IN ('random', 'pile')),
+    ADD COLUMN joker_probability DOUBLE PRECISION NOT NULL DEFAULT 0.3333333333333333
+        CHECK (joker_probability BETWEEN 0 AND 1),
+    ADD COLUMN pity_draws INT NOT NULL DEFAULT 0 CHECK (pity_draws >= 0),
+    ADD COLUMN pile_safe_cards INT NOT NULL DEFAULT 2 CHECK (pile_safe_cards >= 0),
+    ADD COLUMN pile_jokers INT NOT NULL DEFAULT 1 CHECK (pile_jokers >= 0),
+    -- draw state carried between rounds
+    ADD COLUMN draws_since_joker INT NOT NULL DEFAULT 0,
+    ADD COLUMN pile_safe_left INT NOT NULL DEFAULT 0,
+    ADD COLUMN pile_jokers_left INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE games
+    DROP COLUMN IF EXISTS draw_mode,
+    DROP COLUMN IF EXISTS joker_probability,
+    DROP COLUMN IF EXISTS pity_draws,
+    DROP COLUMN IF EXISTS pile_safe_cards,
+    DROP COLUMN IF EXISTS pile_jokers,
+    DROP COLUMN IF EXISTS draws_since_joker,
+    DROP COLUMN IF EXISTS pile_safe_left,
+    DROP COLUMN IF EXISTS pile_jokers_left;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- seed stays secret until the round is drawn; commitment = sha256(seed) is
+-- published when the round starts, together with draw_input (rules and draw
+-- state the outcome is computed from). Rounds drawn before this migration
+-- never had a seed and stay NULL: they cannot be verified.
+ALTER TABLE rounds
+    ADD COLUMN seed BYTEA,
+    ADD COLUMN commitment TEXT,
+    ADD COLUMN draw_input JSONB;
+
+-- the pending round of each game has not been drawn yet, so it can still
+-- commit to a fresh seed and the game's current draw state. The seed comes
+-- from pgcrypto's CSPRNG and has the same 32 bytes the server draws.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+UPDATE rounds SET seed = gen_random_bytes(32)
+WHERE status = 'pending';
+UPDATE rounds SET commitment = encode(sha256(seed), 'hex')
+WHERE status = 'pending';
+UPDATE rounds r
+SET draw_input = jsonb_build_object(
+    'rules', jsonb_build_object(
+        'drawMode', g.draw_mode,
+        'jokerProbability', g.joker_probability,
+        'pityDraws', g.pity_draws,
+        'pileSafeCards', g.pile_safe_cards,
+        'pileJokers', g.pile_jokers
+    ),
+    'state', jsonb_build_object(
+        'drawsSinceJoker', g.draws_since_joker,
+        'pileSafeLeft', g.pile_safe_left,
+        'pileJokersLeft', g.pile_jokers_left
+    )
+)
+FROM games g
+WHERE g.id = r.game_id AND r.status = 'pending';
+
+ALTER TABLE rounds ADD CONSTRAINT rounds_commitment_check
+    CHECK (num_nulls(seed, commitment, draw_input) IN (0, 3));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE rounds DROP CONSTRAINT IF EXISTS rounds_commitment_check;
+ALTER TABLE rounds
+    DROP COLUMN IF EXISTS seed,
+    DROP COLUMN IF EXISTS commitment,
+    DROP COLUMN IF EXISTS draw_input;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- answer_time_limit_seconds = 0 turns the timer off
+ALTER TABLE games
+    ADD COLUMN answer_time_limit_seconds INT NOT NULL DEFAULT 0
+        CHECK (answer_time_limit_seconds BETWEEN 0 AND 3600),
+    ADD COLUMN timeout_action TEXT NOT NULL DEFAULT 'auto_draw'
+        CHECK (timeout_action IN ('auto_draw', 'skip'));
+
+ALTER TABLE rounds ADD COLUMN deadline_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE rounds DROP CONSTRAINT IF EXISTS rounds_status_check;
+ALTER TABLE rounds ADD CONSTRAINT rounds_status_check
+    CHECK (status IN ('pending', 'revealed', 'done', 'skipped'));
+
+-- timers still running, rebuilt on startup
+CREATE INDEX IF NOT EXISTS rounds_pending_deadline_idx
+    ON rounds (deadline_at)
+    WHERE status = 'pending' AND deadline_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS rounds_pending_deadline_idx;
+
+UPDATE rounds SET status = 'done' WHERE status = 'skipped';
+ALTER TABLE rounds DROP CONSTRAINT IF EXISTS rounds_status_check;
+ALTER TABLE rounds ADD CONSTRAINT rounds_status_check
+    CHECK (status IN ('pending', 'revealed', 'done'));
+
+ALTER TABLE rounds DROP COLUMN IF EXISTS deadline_at;
+
+ALTER TABLE games
+    DROP COLUMN IF EXISTS answer_time_limit_seconds,
+    DROP COLUMN IF EXISTS timeout_action;
+-- +goose StatementEnd
@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+-- vote_rule decides what a rejected answer costs: off (no voting),
+-- penalty (an extra card is drawn) or reanswer (the drawer answers again)
+ALTER TABLE games
+    ADD COLUMN vote_rule TEXT NOT NULL DEFAULT 'off'
+        CHECK (vote_rule IN ('off', 'penalty', 'reanswer'));
+
+-- attempt counts re-answers; penalty marks a rejected answer
+ALTER TABLE rounds
+    ADD COLUMN attempt INT NOT NULL DEFAULT 1,
+    ADD COLUMN penalty BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE rounds DROP CONSTRAINT IF EXISTS rounds_status_check;
+ALTER TABLE rounds ADD CONSTRAINT rounds_status_check
+    CHECK (status IN ('pending', 'voting', 'answered', 'revealed', 'done', 'skipped'));
+
+-- a round waiting for its answer or its vote is still in progress
+DROP INDEX IF EXISTS rounds_one_pending_per_game;
+CREATE UNIQUE INDEX rounds_one_pending_per_game
+    ON rounds (game_id)
+    WHERE status IN ('pending', 'voting', 'answered');
+
+DROP INDEX IF EXISTS rounds_pending_deadline_idx;
+CREATE INDEX rounds_pending_deadline_idx
+    ON rounds (deadline_at)
+    WHERE status IN ('pending', 'voting', 'answered') AND deadline_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS round_votes (
+    round_id BIGINT NOT NULL REFERENCES rounds(id) ON DELETE CASCADE,
+    voter_id BIGINT NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    accept BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (round_id, attempt, voter_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS round_votes;
+
+DROP INDEX IF EXISTS rounds_pending_deadline_idx;
+CREATE INDEX rounds_pending_deadline_idx
+    ON rounds (deadline_at)
+    WHERE status = 'pending' AND deadline_at IS NOT NULL;
+
+UPDATE rounds SET status = 'pending' WHERE status IN ('voting', 'answered');
+DROP INDEX IF EXISTS rounds_one_pending_per_game;
+CREATE UNIQUE INDEX rounds_one_pending_per_game
+    ON rounds (game_id)
+    WHERE status = 'pending';
+
+ALTER TABLE rounds DROP CONSTRAINT IF EXISTS rounds_status_check;
+ALTER TABLE rounds ADD CONSTRAINT rounds_status_check
+    CHECK (status IN ('pending', 'revealed', 'done', 'skipped'));
+
+ALTER TABLE rounds
+    DROP COLUMN IF EXISTS attempt,
+    DROP COLUMN IF EXISTS penalty;
+
+ALTER TABLE games DROP COLUMN IF EXISTS vote_rule;
+-- +goose StatementEnd
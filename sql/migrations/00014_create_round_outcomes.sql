@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- points a drawer earns for each way a round can end
+ALTER TABLE games
+    ADD COLUMN points_safe INT NOT NULL DEFAULT 1 CHECK (points_safe BETWEEN -100 AND 100),
+    ADD COLUMN points_joker INT NOT NULL DEFAULT 0 CHECK (points_joker BETWEEN -100 AND 100),
+    ADD COLUMN points_skipped INT NOT NULL DEFAULT 0 CHECK (points_skipped BETWEEN -100 AND 100),
+    ADD COLUMN points_timed_out INT NOT NULL DEFAULT 0 CHECK (points_timed_out BETWEEN -100 AND 100);
+
+-- one row per finished round; exposed marks a revealed question, which a
+-- timed out round can also end with
+CREATE TABLE IF NOT EXISTS round_outcomes (
+    round_id BIGINT PRIMARY KEY REFERENCES rounds(id) ON DELETE CASCADE,
+    game_id BIGINT NOT NULL REFERENCES games(id) ON DELETE CASCADE,
+    player_id BIGINT NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    outcome TEXT NOT NULL CHECK (outcome IN ('safe', 'joker', 'skipped', 'timed_out')),
+    exposed BOOLEAN NOT NULL DEFAULT FALSE,
+    points INT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS round_outcomes_game_player_idx
+    ON round_outcomes (game_id, player_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS round_outcomes;
+
+ALTER TABLE games
+    DROP COLUMN IF EXISTS points_safe,
+    DROP COLUMN IF EXISTS points_joker,
+    DROP COLUMN IF EXISTS points_skipped,
+    DROP COLUMN IF EXISTS points_timed_out;
+-- +goose StatementEnd
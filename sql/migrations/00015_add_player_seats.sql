@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- players are never deleted once they joined: left_at marks a player who
+-- left or was removed, so their rounds, votes and outcomes stay intact.
+-- seat is the player's fixed place in the turn order.
+ALTER TABLE players
+    ADD COLUMN left_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN seat INT;
+
+UPDATE players p
+SET seat = s.seat
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY game_id ORDER BY joined_at, id) AS seat
+    FROM players
+) s
+WHERE p.id = s.id;
+
+ALTER TABLE players ALTER COLUMN seat SET NOT NULL;
+ALTER TABLE players ADD CONSTRAINT players_game_seat_key UNIQUE (game_id, seat);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE players DROP CONSTRAINT IF EXISTS players_game_seat_key;
+ALTER TABLE players
+    DROP COLUMN IF EXISTS left_at,
+    DROP COLUMN IF EXISTS seat;
+-- +goose StatementEnd
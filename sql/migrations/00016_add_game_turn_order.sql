@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- turn_order decides who draws next: sequential (by seat), random (everybody
+-- once per cycle), loser_picks (whoever drew the joker picks) or
+-- host_assigned (the host picks every turn)
+ALTER TABLE games
+    ADD COLUMN turn_order TEXT NOT NULL DEFAULT 'sequential'
+        CHECK (turn_order IN ('sequential', 'random', 'loser_picks', 'host_assigned'));
+
+-- reordering swaps seats within one statement, so the check has to wait
+-- until the statement ends
+ALTER TABLE players DROP CONSTRAINT IF EXISTS players_game_seat_key;
+ALTER TABLE players ADD CONSTRAINT players_game_seat_key
+    UNIQUE (game_id, seat) DEFERRABLE INITIALLY IMMEDIATE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE players DROP CONSTRAINT IF EXISTS players_game_seat_key;
+ALTER TABLE players ADD CONSTRAINT players_game_seat_key UNIQUE (game_id, seat);
+
+ALTER TABLE games DROP COLUMN IF EXISTS turn_order;
+-- +goose StatementEnd
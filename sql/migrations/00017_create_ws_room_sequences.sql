@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the WebSocket backplane numbers each game's messages here, so every
+-- replica agrees on which event a sequence number stands for
+CREATE TABLE IF NOT EXISTS ws_room_sequences (
+    game_code TEXT PRIMARY KEY,
+    seq BIGINT NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ws_room_sequences;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every replica stamps last_seen_at for the players connected to it, so any
+-- replica can tell whether a player is still online through another one
+ALTER TABLE players ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE players DROP COLUMN IF EXISTS last_seen_at;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keep the oldest of each group of active global questions that only differ
+-- in case, so the unique index below can be built
+UPDATE questions q
+SET deleted_at = now(), updated_at = now()
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY level, lower(content) ORDER BY id) AS n
+    FROM questions
+    WHERE game_id IS NULL AND deleted_at IS NULL
+) d
+WHERE q.id = d.id AND d.n > 1;
+
+-- concurrent imports both pass the duplicate check in Go; the index lets
+-- the second insert skip the row instead of adding it again
+CREATE UNIQUE INDEX IF NOT EXISTS questions_global_content
+    ON questions (level, lower(content))
+    WHERE game_id IS NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS questions_global_content;
+-- +goose StatementEnd
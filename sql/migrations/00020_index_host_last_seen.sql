@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every replica looks for hosts nobody has seen lately on each presence tick
+CREATE INDEX IF NOT EXISTS players_host_last_seen
+    ON players (last_seen_at)
+    WHERE is_host AND left_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS players_host_last_seen;
+-- +goose StatementEnd
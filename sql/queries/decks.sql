@@ -0,0 +1,40 @@
+-- name: CreateDeck :one
+INSERT INTO decks (name, game_id)
+VALUES ($1, $2)
+RETURNING *;
+
+
+-- name: GetPublicDeck :one
+SELECT * FROM decks
+WHERE id = $1 AND game_id IS NULL;
+
+
+-- name: ListPublicDecks :many
+SELECT * FROM decks
+WHERE game_id IS NULL
+ORDER BY id;
+
+
+-- name: AddQuestionToDeck :exec
+INSERT INTO deck_questions (deck_id, question_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+
+-- name: RemoveQuestionFromDeck :execrows
+DELETE FROM deck_questions
+WHERE deck_id = $1 AND question_id = $2;
+
+
+-- name: ListDeckQuestions :many
+SELECT q.* FROM questions q
+JOIN deck_questions dq ON dq.question_id = q.id
+WHERE dq.deck_id = $1 AND q.deleted_at IS NULL
+ORDER BY q.id;
+
+
+-- name: ListDeckQuestionIDs :many
+SELECT q.id FROM questions q
+JOIN deck_questions dq ON dq.question_id = q.id
+WHERE dq.deck_id = $1 AND q.deleted_at IS NULL
+ORDER BY q.id;
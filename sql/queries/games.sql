@@ -1,6 +1,11 @@
 -- name: CreateGame :one
-INSERT INTO games (code, level, status)
-VALUES ($1, $2, $3)
+INSERT INTO games (
+    code, level, status, deck_id,
+    draw_mode, joker_probability, pity_draws, pile_safe_cards, pile_jokers,
+    answer_time_limit_seconds, timeout_action, vote_rule,
+    points_safe, points_joker, points_skipped, points_timed_out, turn_order
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 RETURNING *;
 
 -- name: GetGameByCode :one
@@ -8,6 +13,24 @@ SELECT * FROM games
 WHERE code = $1;
 
 
+-- name: LockGameByCode :one
+SELECT * FROM games
+WHERE code = $1
+FOR UPDATE;
+
+-- name: LockGameByCodeForShare :one
+SELECT * FROM games
+WHERE code = $1
+FOR SHARE;
+
 -- name: UpdateGameStatus :exec
 UPDATE games SET status = $2 WHERE id = $1;
 
+
+-- name: UpdateGameDeck :exec
+UPDATE games SET deck_id = $2, updated_at = $3 WHERE id = $1;
+
+-- name: UpdateGameDrawState :exec
+UPDATE games
+SET draws_since_joker = $2, pile_safe_left = $3, pile_jokers_left = $4
+WHERE id = $1;
@@ -0,0 +1,17 @@
+-- name: CreateRoundOutcome :exec
+INSERT INTO round_outcomes (round_id, game_id, player_id, outcome, exposed, points)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListScoreboard :many
+SELECT p.id AS player_id, p.nickname, p.left_at IS NOT NULL AS left,
+       COALESCE(SUM(o.points), 0)::INT AS points,
+       COUNT(o.round_id) AS rounds,
+       COUNT(o.round_id) FILTER (WHERE o.outcome = 'safe') AS safe,
+       COUNT(o.round_id) FILTER (WHERE o.exposed) AS jokers,
+       COUNT(o.round_id) FILTER (WHERE o.outcome = 'skipped') AS skipped,
+       COUNT(o.round_id) FILTER (WHERE o.outcome = 'timed_out') AS timed_out
+FROM players p
+LEFT JOIN round_outcomes o ON o.player_id = p.id
+WHERE p.game_id = $1
+GROUP BY p.id
+ORDER BY points DESC, jokers, p.id;
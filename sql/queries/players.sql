@@ -1,20 +1,45 @@
 -- name: ListPlayersByGameCode :many
-SELECT p.id, p.nickname, p.is_host, p.joined_at
+SELECT p.id, p.nickname, p.is_host, p.joined_at, p.seat, p.last_seen_at
 FROM players p
 JOIN games g ON p.game_id = g.id
-WHERE g.code = $1
-ORDER BY p.joined_at;
+WHERE g.code = $1 AND p.left_at IS NULL
+ORDER BY p.seat;
 
 
 -- name: CountPlayersInGame :one
-SELECT COUNT(*) FROM players WHERE game_id = $1;
+SELECT COUNT(*) FROM players WHERE game_id = $1 AND left_at IS NULL;
 
 -- name: CreatePlayer :one
-INSERT INTO players(game_id, nickname, is_host)
-VALUES ($1, $2, $3)
-RETURNING id, nickname, is_host, joined_at;
+INSERT INTO players(game_id, nickname, is_host, seat)
+VALUES ($1, $2, $3, (SELECT COALESCE(MAX(seat), 0) + 1 FROM players WHERE game_id = $1))
+RETURNING id, nickname, is_host, joined_at, seat;
 
 
 
--- name: DeletePlayer :exec
-DELETE FROM players WHERE id = $1 AND game_id = $2;
\ No newline at end of file
+-- name: MarkPlayerLeft :exec
+UPDATE players SET left_at = $3, is_host = FALSE
+WHERE id = $1 AND game_id = $2 AND left_at IS NULL;
+
+-- name: GetPlayerInGame :one
+SELECT * FROM players WHERE id = $1 AND game_id = $2;
+
+-- name: SetGameHost :exec
+UPDATE players SET is_host = (id = $2) WHERE game_id = $1;
+
+-- name: TouchPlayers :exec
+UPDATE players SET last_seen_at = sqlc.arg('seen_at')
+WHERE id = ANY(sqlc.arg('ids')::bigint[]);
+
+-- name: ListAbsentHosts :many
+SELECT g.code, p.id
+FROM players p
+JOIN games g ON p.game_id = g.id
+WHERE p.is_host AND p.left_at IS NULL AND g.status <> 'ended'
+  AND p.last_seen_at < sqlc.arg('seen_before')
+ORDER BY g.id;
+
+-- name: UpdatePlayerSeats :exec
+UPDATE players AS p
+SET seat = s.seat
+FROM unnest(sqlc.arg('ids')::bigint[], sqlc.arg('seats')::int[]) AS s(id, seat)
+WHERE p.game_id = sqlc.arg('game_id') AND p.id = s.id;
@@ -0,0 +1,22 @@
+-- name: GetNextQueuedQuestion :one
+SELECT gq.position, q.id, q.content
+FROM game_question_queue gq
+JOIN questions q ON q.id = gq.question_id
+WHERE gq.game_id = $1 AND gq.position >= $2 AND q.deleted_at IS NULL
+ORDER BY gq.position
+LIMIT 1;
+
+
+-- name: ClearQuestionQueue :exec
+DELETE FROM game_question_queue
+WHERE game_id = $1;
+
+
+-- name: FillQuestionQueue :exec
+INSERT INTO game_question_queue (game_id, position, question_id)
+SELECT sqlc.arg('game_id'), t.ord - 1, t.question_id
+FROM unnest(sqlc.arg('question_ids')::bigint[]) WITH ORDINALITY AS t(question_id, ord);
+
+
+-- name: UpdateGameQuestionCursor :exec
+UPDATE games SET question_cursor = $2 WHERE id = $1;
@@ -1,11 +1,74 @@
--- name: GetRandomQuestionByLevel :one
-SELECT id, content FROM questions
-WHERE level = $1
-ORDER BY RANDOM()
-LIMIT 1;
+-- name: ListQuestionIDsByLevel :many
+SELECT id FROM questions
+WHERE level = $1 AND game_id IS NULL AND deleted_at IS NULL
+ORDER BY id;
 
 
 -- name: GetQuestionByID :one
 SELECT content FROM questions
 WHERE id = $1;
 
+
+-- name: CreateQuestion :one
+INSERT INTO questions (level, content, tags)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+
+-- name: ImportQuestion :execrows
+INSERT INTO questions (level, content, tags)
+VALUES ($1, $2, $3)
+ON CONFLICT (level, lower(content)) WHERE game_id IS NULL AND deleted_at IS NULL
+DO NOTHING;
+
+
+-- name: GetActiveQuestion :one
+SELECT * FROM questions
+WHERE id = $1 AND game_id IS NULL AND deleted_at IS NULL;
+
+
+-- name: UpdateQuestion :one
+UPDATE questions
+SET level = $2, content = $3, tags = $4, updated_at = $5
+WHERE id = $1 AND game_id IS NULL AND deleted_at IS NULL
+RETURNING *;
+
+
+-- name: SoftDeleteQuestion :execrows
+UPDATE questions
+SET deleted_at = $2, updated_at = $2
+WHERE id = $1 AND game_id IS NULL AND deleted_at IS NULL;
+
+
+-- name: ListQuestions :many
+SELECT * FROM questions
+WHERE game_id IS NULL AND deleted_at IS NULL
+  AND (sqlc.narg('level')::text IS NULL OR level = sqlc.narg('level'))
+  AND (sqlc.narg('search')::text IS NULL OR content ILIKE '%' || replace(replace(replace(sqlc.narg('search'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+ORDER BY id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+
+-- name: CountQuestions :one
+SELECT COUNT(*) FROM questions
+WHERE game_id IS NULL AND deleted_at IS NULL
+  AND (sqlc.narg('level')::text IS NULL OR level = sqlc.narg('level'))
+  AND (sqlc.narg('search')::text IS NULL OR content ILIKE '%' || replace(replace(replace(sqlc.narg('search'), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\');
+
+
+-- name: ListQuestionContents :many
+SELECT content FROM questions
+WHERE game_id IS NULL AND deleted_at IS NULL;
+
+
+-- name: ExportQuestions :many
+SELECT * FROM questions
+WHERE game_id IS NULL AND deleted_at IS NULL
+  AND (sqlc.narg('level')::text IS NULL OR level = sqlc.narg('level'))
+ORDER BY id;
+
+
+-- name: CreatePrivateQuestion :one
+INSERT INTO questions (level, content, game_id)
+VALUES ($1, $2, $3)
+RETURNING *;
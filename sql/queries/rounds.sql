@@ -12,9 +12,9 @@ LIMIT 1;
 
 
 -- name: CreateRound :one
-INSERT INTO rounds (game_id, question_id, current_player_id, status)
-VALUES ($1, $2, $3, 'pending')
-RETURNING id, question_id, current_player_id, status, created_at;
+INSERT INTO rounds (game_id, question_id, current_player_id, status, seed, commitment, draw_input, deadline_at)
+VALUES ($1, $2, $3, 'pending', $4, $5, $6, $7)
+RETURNING id, question_id, current_player_id, status, created_at, commitment, deadline_at;
 
 
 -- name: GetRoundByID :one
@@ -29,4 +29,40 @@ UPDATE rounds SET is_joker = $2, status = $3 WHERE id = $1;
 SELECT * FROM rounds
 WHERE game_id = $1
 ORDER BY id DESC
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: ListTimedPendingRounds :many
+SELECT r.id, r.current_player_id, r.deadline_at, g.code AS game_code
+FROM rounds r
+JOIN games g ON r.game_id = g.id
+WHERE r.status IN ('pending', 'voting', 'answered')
+  AND r.deadline_at IS NOT NULL AND g.status = 'playing'
+ORDER BY r.deadline_at;
+
+-- name: ClearRoundDeadline :exec
+UPDATE rounds SET deadline_at = NULL WHERE id = $1;
+
+-- name: UpdateRoundPhase :exec
+UPDATE rounds SET status = $2, attempt = $3, penalty = $4 WHERE id = $1;
+
+-- name: CountRoundsInGame :one
+SELECT COUNT(*) FROM rounds WHERE game_id = $1;
+
+-- name: ListRoundDrawers :many
+SELECT current_player_id FROM rounds
+WHERE game_id = $1
+ORDER BY id;
+
+-- name: ListRoundHistory :many
+SELECT r.id, r.current_player_id, p.nickname AS drawer_nickname,
+       r.status, r.is_joker, r.attempt, r.penalty,
+       q.content AS question_content,
+       o.outcome, o.points,
+       r.created_at, o.created_at AS ended_at
+FROM rounds r
+JOIN players p ON r.current_player_id = p.id
+JOIN questions q ON r.question_id = q.id
+LEFT JOIN round_outcomes o ON o.round_id = r.id
+WHERE r.game_id = $1
+ORDER BY r.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
@@ -0,0 +1,15 @@
+-- name: UpsertRoundVote :exec
+INSERT INTO round_votes (round_id, voter_id, attempt, accept)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (round_id, attempt, voter_id) DO UPDATE SET accept = EXCLUDED.accept;
+
+-- name: CountRoundVotes :one
+SELECT COUNT(*) FILTER (WHERE v.accept) AS accepts,
+       COUNT(*) FILTER (WHERE NOT v.accept) AS rejects
+FROM round_votes v
+JOIN players p ON v.voter_id = p.id
+WHERE v.round_id = $1 AND v.attempt = $2 AND p.left_at IS NULL;
+
+-- name: GetRoundVote :one
+SELECT * FROM round_votes
+WHERE round_id = $1 AND attempt = $2 AND voter_id = $3;